@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// CompareCmd runs a single comparison and exits, for scripting and CI checks
+var CompareCmd = &cobra.Command{
+	Use:   "compare [slot]",
+	Short: "Compare a single block between Firehose and RPC Fetcher and exit",
+	Long: `Runs one comparison of the block at the given slot (the Firehose head block when unset),
+prints its result and the differing fields, and exits non-zero on mismatch. No ticker is started
+and Slack is optional. With --once-json, the full comparison result is printed to stdout as JSON
+and nothing else is, logs go to stderr:
+
+  solana-block-qa-tracker compare 250000000
+  solana-block-qa-tracker compare --slot 250000000 --once-json | jq .match`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slot, err := parseCompareSlot(cmd, args)
+		if err != nil {
+			return err
+		}
+		onceJSON, _ := cmd.Flags().GetBool("once-json")
+		notifyOnSuccess, _ := cmd.Flags().GetBool("notify-on-success")
+
+		tracker, err := newTrackerFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		defer tracker.CloseLogged()
+
+		result, err := tracker.CompareSlot(cmd.Context(), slot)
+		tracker.FlushNotifications()
+		if err != nil {
+			return err
+		}
+		if notifyOnSuccess {
+			mismatches := 0
+			if !result.Match {
+				mismatches = 1
+			}
+			tracker.PostRunSummary(fmt.Sprintf("Slot %d", result.Slot), 1, mismatches, 0)
+		}
+
+		out := cmd.OutOrStdout()
+		if onceJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		} else {
+			printComparisonResult(out, result)
+		}
+
+		if !result.Match {
+			return fmt.Errorf("slot %d mismatched", result.Slot)
+		}
+		return nil
+	},
+}
+
+func init() {
+	CompareCmd.Flags().Uint64("slot", 0, "Slot to compare, instead of the positional [slot] (default: the Firehose head block)")
+	CompareCmd.Flags().Bool("once-json", false, "Print the comparison result as JSON to stdout and nothing else, logs go to stderr")
+	CompareCmd.Flags().Bool("notify-on-success", false, "Post a summary to Slack once the comparison completed, including when the block matched")
+}
+
+// parseCompareSlot returns the slot given either as a positional argument or with --slot, 0
+// selects the Firehose head block
+func parseCompareSlot(cmd *cobra.Command, args []string) (uint64, error) {
+	if len(args) == 0 {
+		slot, _ := cmd.Flags().GetUint64("slot")
+		return slot, nil
+	}
+	if cmd.Flags().Changed("slot") {
+		return 0, fmt.Errorf("the slot is given either as a positional argument or with --slot, not both")
+	}
+
+	slot, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid slot %q: %w", args[0], err)
+	}
+	return slot, nil
+}
+
+// printComparisonResult writes a comparison result in human-readable form, with its field diffs
+// on mismatch
+func printComparisonResult(out io.Writer, result *qatracker.Result) {
+	status := "match"
+	if !result.Match {
+		status = "MISMATCH"
+	}
+	fmt.Fprintf(out, "Slot %d: %s\n", result.Slot, status)
+	if result.Skipped {
+		fmt.Fprintf(out, "  Slot was skipped, classified by the skipped slot policy\n")
+		return
+	}
+	fmt.Fprintf(out, "  Firehose checksum:    %s\n", result.FirehoseChecksum)
+	fmt.Fprintf(out, "  RPC Fetcher checksum: %s\n", result.RPCFetcherChecksum)
+	if !result.Match {
+		fmt.Fprintf(out, "  Diff categories:      %v\n", result.DiffCategories)
+		if result.DifferingTransactionCount > 0 {
+			fmt.Fprintf(out, "  Differing transactions: %d\n", result.DifferingTransactionCount)
+			for _, trx := range result.DifferingTransactions {
+				fmt.Fprintf(out, "    #%d %s\n", trx.Index, trx.Signature)
+			}
+		}
+		fmt.Fprintf(out, "  Artifacts:            %s %s\n", result.FirehoseFile, result.RPCFetcherFile)
+		qatracker.PrintFieldDiffs(out, result.FieldDiffs)
+	}
+}
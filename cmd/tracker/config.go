@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// bindFlagsToConfig sets every flag given neither on the command line nor through its QA_*
+// environment variable from the --config file. The file is read by viper, in any format it
+// supports (YAML, TOML, JSON...) according to its extension, and its keys are flag names. Keys
+// naming a flag of another subcommand are passed over so one file can serve every subcommand.
+func bindFlagsToConfig(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		return nil
+	}
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	if err := config.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	known := knownFlagNames(cmd.Root())
+	for _, key := range config.AllKeys() {
+		if !known[key] {
+			return fmt.Errorf("unknown key %q in config file %s, keys are flag names without the leading --", key, path)
+		}
+
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if _, found := os.LookupEnv(qatracker.EnvVarName(flag.Name)); found {
+			continue
+		}
+
+		if err := setFlagFromConfig(cmd.Flags(), flag.Name, config.Get(key)); err != nil {
+			return fmt.Errorf("invalid value for %q in config file %s: %w", key, path, err)
+		}
+	}
+	return nil
+}
+
+// setFlagFromConfig sets a flag from a config file value, a list sets a repeatable flag once
+// per element. Flags set from the file count as explicitly set when validating combinations.
+func setFlagFromConfig(flags *pflag.FlagSet, name string, value any) error {
+	switch value := value.(type) {
+	case nil, map[string]any:
+		return fmt.Errorf("expected a value or a list of values")
+	case []any:
+		for _, item := range value {
+			if err := flags.Set(name, fmt.Sprint(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return flags.Set(name, fmt.Sprint(value))
+	}
+}
+
+// knownFlagNames returns the names of the flags of cmd and of all its subcommands
+func knownFlagNames(cmd *cobra.Command) map[string]bool {
+	names := map[string]bool{}
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			names[flag.Name] = true
+		})
+		cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+			names[flag.Name] = true
+		})
+		for _, sub := range cmd.Commands() {
+			visit(sub)
+		}
+	}
+	visit(cmd)
+	return names
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// DiffCmd compares two previously written block artifacts (JSON or protobuf binary)
+var DiffCmd = &cobra.Command{
+	Use:     "diff <firehose-artifact> <rpc-fetcher-artifact>",
+	Aliases: []string{"replay"},
+	Short:   "Compare two block artifacts written by the tracker",
+	Long: `Loads two block artifacts (.json or .pb, detected from the file extension) and
+re-runs the sanitized checksum comparison on them. When they differ, the differing fields
+are printed, or emitted as a JSON list of field diffs with --format json.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid format %q (valid values: text, json)", format)
+		}
+
+		ignoredFields, err := ignoredFieldsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		firehoseSum, rpcFetcherSum, diffs, err := qatracker.DiffArtifacts(args[0], args[1], ignoredFields)
+		if err != nil {
+			return err
+		}
+		zlog.Info("Artifact checksums calculated",
+			zap.String("firehose_checksum", firehoseSum),
+			zap.String("rpc_fetcher_checksum", rpcFetcherSum))
+
+		out := cmd.OutOrStdout()
+		if format == "json" {
+			if diffs == nil {
+				diffs = []qatracker.FieldDiff{}
+			}
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(diffs); err != nil {
+				return fmt.Errorf("failed to encode field diffs: %w", err)
+			}
+		} else {
+			qatracker.PrintFieldDiffs(out, diffs)
+			if firehoseSum == rpcFetcherSum {
+				fmt.Fprintln(out, "Artifacts are equal")
+			}
+		}
+
+		if firehoseSum != rpcFetcherSum {
+			return fmt.Errorf("artifacts differ: firehose checksum %s, rpc fetcher checksum %s", firehoseSum, rpcFetcherSum)
+		}
+		return nil
+	},
+}
+
+func init() {
+	DiffCmd.Flags().String("format", "text", "Output format: text (human-readable field diffs) or json (list of field diffs, for piping into other tools)")
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// bindFlagsToEnv sets every flag not given on the command line from its QA_* environment
+// variable, so flags always take precedence over the environment. A flag set from the
+// environment counts as explicitly set, like on the command line: it overrides the network
+// defaults and is validated against the other flags.
+func bindFlagsToEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+
+		value, found := os.LookupEnv(qatracker.EnvVarName(flag.Name))
+		if !found {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q in %s for --%s: %w", value, qatracker.EnvVarName(flag.Name), flag.Name, setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// FollowCmd compares every block of a long-lived Firehose stream
+var FollowCmd = &cobra.Command{
+	Use:   "follow",
+	Short: "Keep one Firehose stream open and compare every block as it arrives",
+	Long: `Opens a single long-lived Firehose stream from the head and compares every block with
+RPC Fetcher as it arrives, instead of opening a new stream every interval and comparing only
+its first block. When the stream fails or ends, it is reopened from the cursor of the last
+received block, so no block is skipped. With --state-file, the cursor is persisted after every
+block and a restarted tracker resumes where it left off. The email digest, stats checkpoint,
+telemetry, heartbeat, metrics and health probes run like in the periodic mode. Runs until
+interrupted:
+
+  solana-block-qa-tracker follow --commitment finalized --state-file /var/lib/qa/state.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stateFile, _ := cmd.Flags().GetString("state-file")
+
+		opts := parseMonitoringFlags(cmd).options()
+		if stateFile != "" {
+			opts = append(opts, qatracker.WithSlotState(qatracker.NewSlotState(stateFile)))
+		}
+
+		tracker, err := newTrackerFromFlags(cmd, opts...)
+		if err != nil {
+			return err
+		}
+		defer tracker.CloseLogged()
+
+		err = tracker.Follow(cmd.Context())
+		tracker.FlushNotifications()
+		return err
+	},
+}
+
+func init() {
+	addMonitoringFlags(FollowCmd.Flags())
+	FollowCmd.Flags().String("state-file", "", "JSON file recording the Firehose cursor, the last compared slot and the mismatched slots after every block, the stream resumes from its cursor on startup (disabled when empty)")
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/streamingfast/logging"
 	"go.uber.org/zap"
@@ -13,7 +16,12 @@ func main() {
 	zlog = logging.MustCreateLoggerWithServiceName("solana-block-qa-tracker")
 	defer zlog.Sync()
 
-	if err := RootCmd.Execute(); err != nil {
+	// Commands run under a context cancelled on SIGINT/SIGTERM, so Ctrl+C aborts in-flight
+	// fetches instead of waiting for them to complete
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := RootCmd.ExecuteContext(ctx); err != nil {
 		zlog.Error("Application error", zap.Error(err))
 		os.Exit(1)
 	}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// rangeResultsBatchSize is how many results are inserted at once into the results store in range mode
+const rangeResultsBatchSize = 100
+
+// RangeCmd compares every block of a slot range between Firehose and the RPC fetcher
+var RangeCmd = &cobra.Command{
+	Use:     "range [<start-slot> <stop-slot>]",
+	Aliases: []string{"backfill"},
+	Short:   "Compare every block in a slot range between Firehose and RPC Fetcher",
+	Long: `Compares every final block of [start-slot, stop-slot] between Firehose and RPC Fetcher,
+then prints a summary. The range is given either as two positional slots or with
+--start-slot and --stop-slot. With --state-file, slots a previous run found clean are skipped,
+so an interrupted backfill resumes cheaply. Exits non-zero when any slot mismatched, for CI
+audits:
+
+  solana-block-qa-tracker range --start-slot 250000000 --stop-slot 250000500`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("expected either no argument or <start-slot> <stop-slot>, got %d arguments", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startSlot, stopSlot, err := parseSlotRange(cmd, args)
+		if err != nil {
+			return err
+		}
+		fieldsReport, _ := cmd.Flags().GetBool("compare-fields-report")
+		notifyOnSuccess, _ := cmd.Flags().GetBool("notify-on-success")
+		stateFile, _ := cmd.Flags().GetString("state-file")
+
+		opts := []qatracker.Option{qatracker.WithResultsBatchSize(rangeResultsBatchSize)}
+		if stateFile != "" {
+			opts = append(opts, qatracker.WithSlotState(qatracker.NewSlotState(stateFile)))
+		}
+
+		tracker, err := newTrackerFromFlags(cmd, opts...)
+		if err != nil {
+			return err
+		}
+		defer tracker.CloseLogged()
+
+		summary, err := tracker.CompareRange(cmd.Context(), startSlot, stopSlot)
+		if err != nil {
+			return err
+		}
+		if notifyOnSuccess {
+			tracker.PostRunSummary(fmt.Sprintf("Range %d-%d", startSlot, stopSlot), summary.Compared, summary.Mismatches, summary.Errors)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Compared %d slots in %s (%.1f blocks/s): %d matched, %d mismatches, %d errors, %d skipped slots ignored\n",
+			summary.Compared, summary.Elapsed.Round(time.Millisecond), summary.BlocksPerSecond(), summary.Matched(), summary.Mismatches, summary.Errors, summary.Skipped)
+		if summary.AlreadyClean > 0 {
+			fmt.Fprintf(out, "Skipped %d slots already clean in the state file\n", summary.AlreadyClean)
+		}
+		if len(summary.MismatchedSlots) > 0 {
+			fmt.Fprintf(out, "Mismatched slots: %v\n", summary.MismatchedSlots)
+		}
+		if fieldsReport {
+			summary.PrintFieldsReport(out)
+		}
+
+		if summary.Mismatches > 0 {
+			return fmt.Errorf("%d of %d compared slots mismatched", summary.Mismatches, summary.Compared)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RangeCmd.Flags().Bool("compare-fields-report", false, "Print a breakdown of mismatches by differing field category at the end of the range")
+	RangeCmd.Flags().Uint64("start-slot", 0, "First slot of the range, instead of the positional <start-slot>")
+	RangeCmd.Flags().Uint64("stop-slot", 0, "Last slot of the range (inclusive), instead of the positional <stop-slot>")
+	RangeCmd.Flags().String("state-file", "", "JSON file recording the clean and mismatched slots after every comparison, the slots it records clean are skipped (disabled when empty)")
+	RangeCmd.Flags().Bool("notify-on-success", false, "Post a summary to Slack at the end of the range, including when every slot matched")
+}
+
+// parseSlotRange returns the slot range given either as two positional arguments or with the
+// --start-slot and --stop-slot flags
+func parseSlotRange(cmd *cobra.Command, args []string) (uint64, uint64, error) {
+	flagsSet := cmd.Flags().Changed("start-slot") || cmd.Flags().Changed("stop-slot")
+
+	var startSlot, stopSlot uint64
+	switch {
+	case len(args) == 2 && flagsSet:
+		return 0, 0, fmt.Errorf("the range is given either as positional slots or with --start-slot and --stop-slot, not both")
+	case len(args) == 2:
+		var err error
+		if startSlot, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid start slot %q: %w", args[0], err)
+		}
+		if stopSlot, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid stop slot %q: %w", args[1], err)
+		}
+	case cmd.Flags().Changed("start-slot") && cmd.Flags().Changed("stop-slot"):
+		startSlot, _ = cmd.Flags().GetUint64("start-slot")
+		stopSlot, _ = cmd.Flags().GetUint64("stop-slot")
+	default:
+		return 0, 0, fmt.Errorf("a slot range is required: <start-slot> <stop-slot> or --start-slot and --stop-slot")
+	}
+
+	if stopSlot < startSlot {
+		return 0, 0, fmt.Errorf("stop slot %d is before start slot %d", stopSlot, startSlot)
+	}
+	return startSlot, stopSlot, nil
+}
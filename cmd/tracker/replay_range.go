@@ -0,0 +1,185 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// artifactSides lists the artifact name of each source, a pair is diffed in this order so the
+// Firehose side (or the live side against an archive) always comes first
+var artifactSides = []string{"firehose_block", "rpc_fetcher_block", "archived_block", "reflect_decoded_block"}
+
+// artifactNamePattern matches artifact filenames, e.g. mainnet-firehose_block_250000000.pb
+var artifactNamePattern = regexp.MustCompile(`^(.*?)(` + strings.Join(artifactSides, "|") + `)_(\d+)\.(json|pb)$`)
+
+// ReplayRangeCmd re-diffs every block artifact pair of a directory with the current comparison logic
+var ReplayRangeCmd = &cobra.Command{
+	Use:   "replay-range",
+	Short: "Re-run the comparison on every block artifact pair of a directory and print an aggregate report",
+	Long: `Walks --dir for block artifact pairs written by the tracker (same prefix, slot and format,
+e.g. firehose_block_<slot>.pb and rpc_fetcher_block_<slot>.pb), re-runs the sanitized checksum
+comparison on each pair and prints an aggregate report. Use it to check that a change to the
+comparison or sanitization logic produces the expected results across a captured corpus
+before deploying it:
+
+  solana-block-qa-tracker replay-range --dir captures/`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		if dir == "" {
+			return fmt.Errorf("--dir is required")
+		}
+
+		ignoredFields, err := ignoredFieldsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		pairs, err := findArtifactPairs(dir)
+		if err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			return fmt.Errorf("no block artifact pairs found in %s", dir)
+		}
+
+		report := &replayReport{Categories: map[string]int{}, Programs: map[string]int{}}
+		for _, pair := range pairs {
+			_, _, diffs, err := qatracker.DiffArtifacts(pair.files[0], pair.files[1], ignoredFields)
+			if err != nil {
+				zlog.Error("Failed to replay artifact pair", zap.Uint64("slot", pair.slot), zap.Error(err))
+				report.Errors++
+				continue
+			}
+			report.add(pair.slot, diffs)
+		}
+
+		report.print(cmd.OutOrStdout())
+		return nil
+	},
+}
+
+func init() {
+	ReplayRangeCmd.Flags().String("dir", "", "Directory holding the block artifacts to replay (required)")
+}
+
+// artifactPair is the pair of artifacts written for one mismatching slot
+type artifactPair struct {
+	slot  uint64
+	files [2]string
+}
+
+// findArtifactPairs returns the artifact pairs of dir sorted by slot. Artifacts without a
+// counterpart are logged and skipped.
+func findArtifactPairs(dir string) ([]artifactPair, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact directory %s: %w", dir, err)
+	}
+
+	// Artifacts of one pair share their prefix, slot and format
+	type pairKey struct {
+		prefix string
+		slot   uint64
+		format string
+	}
+	sides := map[pairKey]map[string]string{}
+	for _, entry := range entries {
+		match := artifactNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		slot, err := strconv.ParseUint(match[3], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		key := pairKey{prefix: match[1], slot: slot, format: match[4]}
+		if sides[key] == nil {
+			sides[key] = map[string]string{}
+		}
+		sides[key][match[2]] = filepath.Join(dir, entry.Name())
+	}
+
+	var pairs []artifactPair
+	for key, files := range sides {
+		var ordered []string
+		for _, side := range artifactSides {
+			if file, found := files[side]; found {
+				ordered = append(ordered, file)
+			}
+		}
+		if len(ordered) != 2 {
+			zlog.Warn("Skipping artifacts without exactly one counterpart", zap.Uint64("slot", key.slot), zap.Strings("files", ordered))
+			continue
+		}
+		pairs = append(pairs, artifactPair{slot: key.slot, files: [2]string{ordered[0], ordered[1]}})
+	}
+
+	slices.SortFunc(pairs, func(a, b artifactPair) int {
+		if a.slot != b.slot {
+			return cmp.Compare(a.slot, b.slot)
+		}
+		return cmp.Compare(a.files[0], b.files[0])
+	})
+	return pairs, nil
+}
+
+// replayReport aggregates the results of replaying artifact pairs
+type replayReport struct {
+	Replayed      int
+	Matching      int
+	Errors        int
+	DifferedSlots []uint64
+	Categories    map[string]int
+	Programs      map[string]int
+}
+
+func (r *replayReport) add(slot uint64, diffs []qatracker.FieldDiff) {
+	r.Replayed++
+	if len(diffs) == 0 {
+		r.Matching++
+		return
+	}
+
+	r.DifferedSlots = append(r.DifferedSlots, slot)
+	for _, category := range qatracker.FieldDiffCategories(diffs) {
+		r.Categories[category]++
+	}
+	for program, count := range qatracker.ProgramTally(diffs) {
+		r.Programs[program] += count
+	}
+}
+
+// print writes the aggregate report, tallies are sorted most frequent first
+func (r *replayReport) print(w io.Writer) {
+	fmt.Fprintf(w, "Replayed %d artifact pairs: %d now match, %d still differ, %d errors\n",
+		r.Replayed, r.Matching, len(r.DifferedSlots), r.Errors)
+	if len(r.DifferedSlots) > 0 {
+		fmt.Fprintf(w, "Differing slots: %v\n", r.DifferedSlots)
+	}
+	if len(r.Categories) > 0 {
+		fmt.Fprintf(w, "Diff categories over %d differing pairs:\n", len(r.DifferedSlots))
+		for _, category := range qatracker.TopCategories(r.Categories, 0) {
+			fmt.Fprintf(w, "  %-24s %d\n", category, r.Categories[category])
+		}
+	}
+	if len(r.Programs) > 0 {
+		fmt.Fprintln(w, "Differing instructions by program:")
+		for _, program := range qatracker.TopCategories(r.Programs, 0) {
+			fmt.Fprintf(w, "  %-44s %d\n", program, r.Programs[program])
+		}
+	}
+}
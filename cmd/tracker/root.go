@@ -2,9 +2,17 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"solana-block-qa-tracker/pkg/qatracker"
 )
 
 // RootCmd is the exported cobra command that can be used by main.go
@@ -14,25 +22,693 @@ var RootCmd = &cobra.Command{
 	Long: `Solana Block QA Tracker compares blocks between StreamingFast Firehose and RPC Fetcher 
 to ensure data consistency. It runs periodic comparisons at the specified interval.`,
 	Args: cobra.ExactArgs(1),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := bindFlagsToEnv(cmd); err != nil {
+			return err
+		}
+		return bindFlagsToConfig(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		interval, err := time.ParseDuration(args[0])
+		config, err := parseRootArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		tracker, err := newTrackerFromFlags(cmd, config.options()...)
 		if err != nil {
-			return fmt.Errorf("invalid interval format: %w (examples: 30s, 5m, 1h)", err)
+			return err
 		}
-		slackWebhookURL, _ := cmd.Flags().GetString("slack-webhook-url")
-		slackChannel, _ := cmd.Flags().GetString("slack-channel")
-		firehoseEndpoint, _ := cmd.Flags().GetString("firehose-endpoint")
-		solanaRPCEndpoint, _ := cmd.Flags().GetString("solana-rpc-endpoint")
+		defer tracker.CloseLogged()
 
-		// Create a new Tracker instance
-		tracker := NewTracker(zlog, slackWebhookURL, slackChannel, firehoseEndpoint, solanaRPCEndpoint)
-		return tracker.runTracker(interval)
+		return tracker.Run(cmd.Context(), config.Interval)
 	},
 }
 
+// rootConfig holds the settings specific to the periodic comparison run by the root command
+type rootConfig struct {
+	Interval             time.Duration
+	ShutdownTimeout      time.Duration
+	ComparisonTimeout    time.Duration
+	Batch                int
+	MaxConsecutiveErrors int
+	StateFile            string
+	monitoringConfig
+}
+
+// monitoringConfig holds the heartbeat, metrics and health probes settings of the commands
+// running until interrupted
+type monitoringConfig struct {
+	HeartbeatInterval time.Duration
+	MetricsListenAddr string
+	HealthListenAddr  string
+	HealthStaleness   time.Duration
+}
+
+// parseRootArgs parses and validates the interval argument and the root-only flags, without
+// connecting to any endpoint
+func parseRootArgs(cmd *cobra.Command, args []string) (rootConfig, error) {
+	interval, err := time.ParseDuration(args[0])
+	if err != nil {
+		return rootConfig{}, fmt.Errorf("invalid interval format: %w (examples: 30s, 5m, 1h)", err)
+	}
+
+	minInterval, _ := cmd.Flags().GetDuration("min-interval")
+	if interval < minInterval {
+		return rootConfig{}, fmt.Errorf("interval %s is below the minimum allowed interval %s (lower --min-interval to override)", interval, minInterval)
+	}
+
+	config := rootConfig{Interval: interval}
+	config.ShutdownTimeout, _ = cmd.Flags().GetDuration("shutdown-timeout")
+	config.ComparisonTimeout, _ = cmd.Flags().GetDuration("comparison-timeout")
+	config.Batch, _ = cmd.Flags().GetInt("batch")
+	config.MaxConsecutiveErrors, _ = cmd.Flags().GetInt("max-consecutive-errors")
+	config.StateFile, _ = cmd.Flags().GetString("state-file")
+	config.monitoringConfig = parseMonitoringFlags(cmd)
+	return config, nil
+}
+
+// parseMonitoringFlags reads the flags registered by addMonitoringFlags
+func parseMonitoringFlags(cmd *cobra.Command) monitoringConfig {
+	var config monitoringConfig
+	config.HeartbeatInterval, _ = cmd.Flags().GetDuration("heartbeat-interval")
+	config.MetricsListenAddr, _ = cmd.Flags().GetString("metrics-listen-addr")
+	config.HealthListenAddr, _ = cmd.Flags().GetString("health-listen-addr")
+	config.HealthStaleness, _ = cmd.Flags().GetDuration("health-staleness")
+	return config
+}
+
+// options returns the Tracker options of the root-only settings
+func (c rootConfig) options() []qatracker.Option {
+	opts := []qatracker.Option{qatracker.WithShutdownTimeout(c.ShutdownTimeout), qatracker.WithComparisonTimeout(c.ComparisonTimeout), qatracker.WithMaxConsecutiveErrors(c.MaxConsecutiveErrors)}
+	if c.Batch > 0 {
+		opts = append(opts, qatracker.WithBatch(c.Batch))
+	}
+	if c.StateFile != "" {
+		opts = append(opts, qatracker.WithSlotState(qatracker.NewSlotState(c.StateFile)))
+	}
+	return append(opts, c.monitoringConfig.options()...)
+}
+
+// options returns the Tracker options of the monitoring settings
+func (c monitoringConfig) options() []qatracker.Option {
+	opts := []qatracker.Option{qatracker.WithHeartbeat(c.HeartbeatInterval)}
+	if c.MetricsListenAddr != "" {
+		opts = append(opts, qatracker.WithMetrics(qatracker.NewMetrics(c.MetricsListenAddr)))
+	}
+	if c.HealthListenAddr != "" {
+		opts = append(opts, qatracker.WithProbes(qatracker.NewProbes(c.HealthListenAddr, c.HealthStaleness)))
+	}
+	return opts
+}
+
+// newTrackerFromFlags builds a Tracker from the root persistent flags shared by all commands,
+// extraOpts are applied last so command specific settings take precedence
+func newTrackerFromFlags(cmd *cobra.Command, extraOpts ...qatracker.Option) (*qatracker.Tracker, error) {
+	if err := validateFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
+
+	slackWebhookURL, _ := cmd.Flags().GetString("slack-webhook-url")
+	slackChannel, _ := cmd.Flags().GetString("slack-channel")
+	notifyWebhookURL, _ := cmd.Flags().GetString("notify-webhook-url")
+	notifyWebhookTemplate, _ := cmd.Flags().GetString("notify-webhook-template")
+	notifierSpecs, _ := cmd.Flags().GetStringArray("notifier")
+	pagerDutyRoutingKey, _ := cmd.Flags().GetString("pagerduty-routing-key")
+	pagerDutyDedupValue, _ := cmd.Flags().GetString("pagerduty-dedup")
+	firehoseAPIToken, _ := cmd.Flags().GetString("firehose-api-token")
+	firehoseAPIKey, _ := cmd.Flags().GetString("firehose-api-key")
+	artifactFormatValue, _ := cmd.Flags().GetString("artifact-format")
+	artifactScopeValue, _ := cmd.Flags().GetString("artifact-scope")
+	mismatchRateThreshold, _ := cmd.Flags().GetFloat64("mismatch-rate-threshold")
+	mismatchRateWindow, _ := cmd.Flags().GetInt("mismatch-rate-window")
+	filterProgram, _ := cmd.Flags().GetString("filter-program")
+	digestSchedule, _ := cmd.Flags().GetString("digest-schedule")
+	decoderCheck, _ := cmd.Flags().GetBool("decoder-check")
+	artifactProtoNames, _ := cmd.Flags().GetBool("artifact-proto-names")
+	dumpRawBytes, _ := cmd.Flags().GetBool("dump-raw-bytes")
+	artifactQueueSize, _ := cmd.Flags().GetInt("artifact-queue-size")
+	writeDiffFile, _ := cmd.Flags().GetBool("write-diff-file")
+	maxDifferingTransactions, _ := cmd.Flags().GetInt("max-differing-transactions")
+	skippedSlotPolicyValue, _ := cmd.Flags().GetString("skipped-slot-policy")
+	normalizeOrder, _ := cmd.Flags().GetBool("normalize-order")
+	checkLinkage, _ := cmd.Flags().GetBool("check-linkage")
+	notifyOnRecoveryOnly, _ := cmd.Flags().GetBool("notify-on-recovery-only")
+	alertCooldown, _ := cmd.Flags().GetDuration("alert-cooldown")
+	compareFinalized, _ := cmd.Flags().GetBool("compare-finalized")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	outputPrefix, _ := cmd.Flags().GetString("output-prefix")
+	outputStoreURL, _ := cmd.Flags().GetString("output-store")
+	outputStorePublicURL, _ := cmd.Flags().GetString("output-store-public-url")
+	outputStoreSignedURLTTL, _ := cmd.Flags().GetDuration("output-store-signed-url-ttl")
+	firehoseWaitForReady, _ := cmd.Flags().GetBool("firehose-wait-for-ready")
+	firehoseReadyTimeout, _ := cmd.Flags().GetDuration("firehose-ready-timeout")
+	firehoseRecvTimeout, _ := cmd.Flags().GetDuration("firehose-recv-timeout")
+	firehoseReconnectAttempts, _ := cmd.Flags().GetInt("firehose-reconnect-attempts")
+	headStallTimeout, _ := cmd.Flags().GetDuration("head-stall-timeout")
+	firehoseFetchTimeout, _ := cmd.Flags().GetDuration("firehose-fetch-timeout")
+	rpcFetchTimeout, _ := cmd.Flags().GetDuration("rpc-fetch-timeout")
+	resultsPostgres, _ := cmd.Flags().GetString("results-postgres")
+	resultsSQLite, _ := cmd.Flags().GetString("results-sqlite")
+	resultsLogPath, _ := cmd.Flags().GetString("results-log")
+	grpcListenAddr, _ := cmd.Flags().GetString("grpc-listen-addr")
+	rpcQuorum, _ := cmd.Flags().GetInt("rpc-quorum")
+	sanitizeModeValue, _ := cmd.Flags().GetString("sanitize-mode")
+	checksumScopeValue, _ := cmd.Flags().GetString("checksum-scope")
+	blocksStoreURL, _ := cmd.Flags().GetString("blocks-store")
+	peerFirehoseEndpoint, _ := cmd.Flags().GetString("peer-firehose-endpoint")
+	geyserEndpoint, _ := cmd.Flags().GetString("geyser-endpoint")
+	bigtableProject, _ := cmd.Flags().GetString("bigtable-project")
+	blockTimeTolerance, _ := cmd.Flags().GetInt64("blocktime-tolerance")
+	minFreeMemoryMiB, _ := cmd.Flags().GetUint64("min-free-memory")
+	maxConcurrentComparisons, _ := cmd.Flags().GetInt("max-concurrent-comparisons")
+	prefetchDepth, _ := cmd.Flags().GetInt("prefetch-depth")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	rpcBatchSize, _ := cmd.Flags().GetInt("rpc-batch-size")
+	statsFlushInterval, _ := cmd.Flags().GetDuration("stats-flush-interval")
+	telemetry, _ := cmd.Flags().GetBool("telemetry")
+	seed, _ := cmd.Flags().GetUint64("seed")
+
+	artifactFormat, err := qatracker.ParseArtifactFormat(artifactFormatValue)
+	if err != nil {
+		return nil, err
+	}
+	artifactScope, err := qatracker.ParseArtifactScope(artifactScopeValue)
+	if err != nil {
+		return nil, err
+	}
+	sanitizeMode, err := qatracker.ParseSanitizeMode(sanitizeModeValue)
+	if err != nil {
+		return nil, err
+	}
+	ignoredFields, err := ignoredFieldsFromFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	checksumScope, err := qatracker.ParseChecksumScope(checksumScopeValue)
+	if err != nil {
+		return nil, err
+	}
+	skippedSlotPolicy, err := qatracker.ParseSkippedSlotPolicy(skippedSlotPolicyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := parseEndpointFlags(cmd)
+	if err != nil {
+		return nil, err
+	}
+	fetcherConfig, firehoseEndpoint, solanaRPCEndpoint, rpcProviders := endpoints.Fetcher, endpoints.FirehoseEndpoint, endpoints.SolanaRPCEndpoint, endpoints.RPCProviders
+	// A minority quorum could be reached both by providers agreeing and disagreeing with Firehose
+	if total := len(rpcProviders) + 1; rpcQuorum > 0 && (rpcQuorum*2 <= total || rpcQuorum > total) {
+		return nil, fmt.Errorf("--rpc-quorum %d must be a majority of the %d --solana-rpc-endpoint providers (%d to %d)", rpcQuorum, total, total/2+1, total)
+	}
+
+	// The unprefixed variables predate the QA_* binding and are still honored
+	if firehoseAPIToken == "" {
+		firehoseAPIToken = os.Getenv("FIREHOSE_API_TOKEN")
+	}
+	if firehoseAPIKey == "" {
+		firehoseAPIKey = os.Getenv("FIREHOSE_API_KEY")
+	}
+
+	opts := []qatracker.Option{
+		qatracker.WithFirehoseAuth(firehoseAPIToken, firehoseAPIKey),
+		qatracker.WithArtifactFormat(artifactFormat),
+		qatracker.WithArtifactScope(artifactScope),
+		qatracker.WithOutputLocation(outputDir, outputPrefix),
+		qatracker.WithSanitizeMode(sanitizeMode),
+		qatracker.WithIgnoredFields(ignoredFields),
+		qatracker.WithChecksumScope(checksumScope),
+		qatracker.WithFetcherConfig(fetcherConfig),
+		qatracker.WithSkippedSlotPolicy(skippedSlotPolicy),
+		qatracker.WithMaxConcurrentComparisons(maxConcurrentComparisons),
+		qatracker.WithFetchTimeouts(firehoseFetchTimeout, rpcFetchTimeout),
+		qatracker.WithMaxDifferingTransactions(maxDifferingTransactions),
+	}
+	if mismatchRateThreshold > 0 {
+		opts = append(opts, qatracker.WithMismatchRateAlert(mismatchRateWindow, mismatchRateThreshold))
+	}
+	if filterProgram != "" {
+		programID, err := solana.PublicKeyFromBase58(filterProgram)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --filter-program public key %q: %w", filterProgram, err)
+		}
+		opts = append(opts, qatracker.WithProgramFilter(programID))
+	}
+	if digestSchedule != "" {
+		schedule, err := qatracker.ParseDigestSchedule(digestSchedule)
+		if err != nil {
+			return nil, err
+		}
+		smtpConfig := qatracker.SMTPConfig{}
+		smtpConfig.Host, _ = cmd.Flags().GetString("smtp-host")
+		smtpConfig.Port, _ = cmd.Flags().GetInt("smtp-port")
+		smtpConfig.Username, _ = cmd.Flags().GetString("smtp-username")
+		smtpConfig.Password, _ = cmd.Flags().GetString("smtp-password")
+		smtpConfig.From, _ = cmd.Flags().GetString("smtp-from")
+		smtpConfig.To, _ = cmd.Flags().GetStringSlice("smtp-to")
+
+		emailNotifier, err := qatracker.NewEmailNotifier(smtpConfig, schedule)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithEmailDigest(emailNotifier))
+	}
+
+	if statsFlushInterval > 0 {
+		statsFile, _ := cmd.Flags().GetString("stats-file")
+		statsResume, _ := cmd.Flags().GetBool("stats-resume")
+
+		matchRateWindow, _ := cmd.Flags().GetDuration("compare-rate-window")
+
+		statsCheckpoint, err := qatracker.NewStatsCheckpoint(statsFile, statsFlushInterval, matchRateWindow, statsResume)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithStatsCheckpoint(statsCheckpoint))
+	}
+
+	if telemetry {
+		telemetryURL, _ := cmd.Flags().GetString("telemetry-url")
+		telemetryInterval, _ := cmd.Flags().GetDuration("telemetry-interval")
+
+		reporter, err := qatracker.NewTelemetryReporter(telemetryURL, telemetryInterval, firehoseEndpoint, solanaRPCEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithTelemetry(reporter))
+	}
+
+	if blocksStoreURL != "" {
+		blocksStoreAgainstValue, _ := cmd.Flags().GetString("blocks-store-against")
+		blocksStoreAgainst, err := qatracker.ParseBlocksStoreAgainst(blocksStoreAgainstValue)
+		if err != nil {
+			return nil, err
+		}
+
+		blocksStore, err := qatracker.NewBlocksStore(blocksStoreURL, blocksStoreAgainst)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithBlocksStore(blocksStore))
+	}
+
+	if peerFirehoseEndpoint != "" {
+		peerFirehoseAPIToken, _ := cmd.Flags().GetString("peer-firehose-api-token")
+		peerFirehoseAPIKey, _ := cmd.Flags().GetString("peer-firehose-api-key")
+
+		peerFirehose, err := qatracker.NewPeerFirehose(peerFirehoseEndpoint, peerFirehoseAPIToken, peerFirehoseAPIKey)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithPeerFirehose(peerFirehose))
+	}
+
+	if geyserEndpoint != "" {
+		geyserXToken, _ := cmd.Flags().GetString("geyser-x-token")
+
+		geyserSource, err := qatracker.NewGeyserSource(geyserEndpoint, geyserXToken)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithBlockSource(geyserSource))
+	}
+
+	if bigtableProject != "" {
+		bigtableInstance, _ := cmd.Flags().GetString("bigtable-instance")
+		bigtableCredentials, _ := cmd.Flags().GetString("bigtable-credentials")
+
+		bigtableSource, err := qatracker.NewBigtableSource(cmd.Context(), bigtableProject, bigtableInstance, bigtableCredentials)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithBlockSource(bigtableSource))
+	}
+
+	if outputStoreURL != "" {
+		outputStore, err := qatracker.NewOutputStore(outputStoreURL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithOutputStore(outputStore))
+
+		if outputStorePublicURL != "" {
+			opts = append(opts, qatracker.WithArtifactPublicURL(outputStorePublicURL))
+		}
+		if outputStoreSignedURLTTL > 0 {
+			signer, err := qatracker.NewArtifactURLSigner(cmd.Context(), outputStoreURL, outputStoreSignedURLTTL)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, qatracker.WithArtifactURLSigner(signer))
+		}
+	}
+
+	if resultsLogPath != "" {
+		resultsLog, err := qatracker.OpenResultsLog(resultsLogPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithResultsLog(resultsLog))
+	}
+
+	if notifyWebhookURL != "" && notifyWebhookTemplate != "" {
+		tmpl, err := qatracker.ParseWebhookTemplate(notifyWebhookTemplate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithNotifier(qatracker.NewTemplatedWebhookNotifier(notifyWebhookURL, tmpl)))
+	} else if notifyWebhookURL != "" {
+		opts = append(opts, qatracker.WithNotifier(qatracker.NewWebhookNotifier(notifyWebhookURL)))
+	}
+	if pagerDutyRoutingKey != "" {
+		pagerDutyDedup, err := qatracker.ParsePagerDutyDedup(pagerDutyDedupValue)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithNotifier(qatracker.NewPagerDutyNotifier(pagerDutyRoutingKey, pagerDutyDedup, zlog)))
+	}
+	for _, spec := range notifierSpecs {
+		notifier, err := qatracker.ParseNotifier(spec, zlog)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithNotifier(notifier))
+	}
+
+	if cmd.Flags().Changed("seed") {
+		opts = append(opts, qatracker.WithSeed(seed))
+	}
+	if decoderCheck {
+		opts = append(opts, qatracker.WithDecoderCheck())
+	}
+	if artifactProtoNames {
+		opts = append(opts, qatracker.WithArtifactProtoNames())
+	}
+	if dumpRawBytes {
+		opts = append(opts, qatracker.WithDumpRawBytes())
+	}
+	if writeDiffFile {
+		opts = append(opts, qatracker.WithDiffFile())
+	}
+	if artifactQueueSize > 0 {
+		opts = append(opts, qatracker.WithArtifactQueue(artifactQueueSize))
+	}
+	if normalizeOrder {
+		opts = append(opts, qatracker.WithNormalizeOrder())
+	}
+	if checkLinkage {
+		opts = append(opts, qatracker.WithLinkageCheck())
+	}
+	if compareFinalized {
+		opts = append(opts, qatracker.WithCompareFinalized())
+	}
+	if notifyOnRecoveryOnly {
+		opts = append(opts, qatracker.WithNotifyOnRecoveryOnly())
+	}
+	if alertCooldown > 0 {
+		opts = append(opts, qatracker.WithAlertCooldown(alertCooldown))
+	}
+	if firehoseWaitForReady {
+		opts = append(opts, qatracker.WithFirehoseWaitForReady(firehoseReadyTimeout))
+	}
+	if blockTimeTolerance > 0 {
+		opts = append(opts, qatracker.WithBlockTimeTolerance(blockTimeTolerance))
+	}
+	if firehoseRecvTimeout > 0 {
+		opts = append(opts, qatracker.WithFirehoseRecvTimeout(firehoseRecvTimeout))
+	}
+	if firehoseReconnectAttempts > 0 {
+		// Credentials given on the command line are fixed, only environment ones are refreshed
+		fromEnv := !cmd.Flags().Changed("firehose-api-token") && !cmd.Flags().Changed("firehose-api-key")
+		opts = append(opts, qatracker.WithFirehoseReconnect(firehoseReconnectAttempts, fromEnv))
+	}
+	if rpcBatchSize > 1 {
+		opts = append(opts, qatracker.WithRPCBatchSize(rpcBatchSize))
+	}
+	if prefetchDepth > 0 {
+		opts = append(opts, qatracker.WithPrefetch(prefetchDepth))
+	}
+	if concurrency > 1 {
+		opts = append(opts, qatracker.WithConcurrency(concurrency))
+	}
+	if headStallTimeout > 0 {
+		opts = append(opts, qatracker.WithHeadStallTimeout(headStallTimeout))
+	}
+	if minFreeMemoryMiB > 0 {
+		opts = append(opts, qatracker.WithMinFreeMemory(minFreeMemoryMiB*1024*1024))
+	}
+	if resultsPostgres != "" {
+		sink, err := qatracker.NewPostgresSink(cmd.Context(), resultsPostgres, outputPrefix)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithResultsSink(sink))
+	}
+	if resultsSQLite != "" {
+		sink, err := qatracker.NewSQLiteSink(cmd.Context(), resultsSQLite, outputPrefix)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, qatracker.WithResultsSink(sink))
+	}
+
+	if len(rpcProviders) > 0 {
+		opts = append(opts, qatracker.WithRPCProviders(rpcProviders))
+	}
+	if rpcQuorum > 0 {
+		opts = append(opts, qatracker.WithRPCQuorum(rpcQuorum))
+	}
+
+	if grpcListenAddr != "" {
+		opts = append(opts, qatracker.WithGRPCServer(qatracker.NewGRPCServer(grpcListenAddr)))
+	}
+
+	opts = append(opts, extraOpts...)
+
+	// Create a new Tracker instance
+	return qatracker.NewTracker(zlog, slackWebhookURL, slackChannel, firehoseEndpoint, solanaRPCEndpoint, opts...)
+}
+
 func init() {
-	RootCmd.Flags().String("slack-webhook-url", "", "Slack webhook URL for notifications")
-	RootCmd.Flags().String("slack-channel", "solana", "Slack channel for notifications (default: #general)")
-	RootCmd.Flags().String("firehose-endpoint", "mainnet.sol.streamingfast.io:443", "StreamingFast Solana Firehose endpoint")
-	RootCmd.Flags().String("solana-rpc-endpoint", "https://api.mainnet-beta.solana.com", "Solana RPC endpoint")
+	addRootFlags(RootCmd.Flags())
+	addPersistentFlags(RootCmd.PersistentFlags())
+
+	RootCmd.AddCommand(DiffCmd)
+	RootCmd.AddCommand(RangeCmd)
+	RootCmd.AddCommand(SLACmd)
+	RootCmd.AddCommand(CompareCmd)
+	RootCmd.AddCommand(SelfCheckCmd)
+	RootCmd.AddCommand(ReplayRangeCmd)
+	RootCmd.AddCommand(FollowCmd)
+	RootCmd.AddCommand(StatsCmd)
+	RootCmd.AddCommand(ServeCmd)
+}
+
+// addRootFlags registers the flags of the periodic comparison run by the root command
+func addRootFlags(flags *pflag.FlagSet) {
+	addMonitoringFlags(flags)
+	flags.Duration("min-interval", time.Second, "Minimum allowed comparison interval, protects endpoints from accidental hammering")
+	flags.Int("batch", 0, "Compare, on every tick, the N newest finalized slots not compared yet instead of only the head block (0 disables)")
+	flags.Duration("shutdown-timeout", 30*time.Second, "Time given to the aborted in-flight comparison and pending notifications on shutdown before forcing exit")
+	flags.Duration("comparison-timeout", 0, "Deadline of each periodic comparison, both fetches included, a comparison exceeding it fails as a network error (0 disables)")
+	flags.String("state-file", "", "JSON file recording the last compared slot and the mismatched slots after every comparison, loaded on startup so --batch resumes after the slots already compared (disabled when empty)")
+	flags.Int("max-consecutive-errors", 0, "Exit non-zero once more than this many comparisons in a row failed, so a supervisor restarts the tracker clean (0 never gives up)")
+}
+
+// addMonitoringFlags registers the heartbeat, metrics and health probes flags of the commands
+// running until interrupted, read by parseMonitoringFlags
+func addMonitoringFlags(flags *pflag.FlagSet) {
+	flags.Duration("heartbeat-interval", time.Minute, "Log a heartbeat at this interval, independently of the comparisons, so an idle tracker can be told from a hung one (0 disables)")
+	flags.String("metrics-listen-addr", "", "Serve Prometheus metrics (comparisons, mismatches, fetch latencies, last compared slot) on this address, e.g. :9102 (disabled when empty)")
+	flags.String("health-listen-addr", "", "Serve the /healthz liveness and /readyz readiness probes on this address, e.g. :8080 (disabled when empty)")
+	flags.Duration("health-staleness", 5*time.Minute, "Report not ready once no comparison completed within this window")
+}
+
+// addPersistentFlags registers the flags shared by every command
+func addPersistentFlags(flags *pflag.FlagSet) {
+	flags.String("config", "", "YAML, TOML or JSON file setting flags by name, flags given on the command line or through QA_* environment variables take precedence")
+	flags.String("slack-webhook-url", "", "Slack webhook URL for notifications")
+	flags.String("slack-channel", "solana", "Slack channel for notifications (default: #general)")
+	flags.String("notify-webhook-url", "", "Also POST every mismatch alert as a JSON event to this webhook, next to Slack (disabled when empty)")
+	flags.String("notify-webhook-template", "", "Go template file rendering the JSON payload POSTed to --notify-webhook-url from each mismatch event, instead of the event itself")
+	flags.StringArray("notifier", nil, "Additional notification backend mismatch alerts are delivered to, as name:key=value,... e.g. webhook:url=https://alerts.example.com/qa (repeatable, backends: "+strings.Join(qatracker.RegisteredNotifiers(), ", ")+")")
+	flags.String("pagerduty-routing-key", "", "Integration key of a PagerDuty Events API v2 service, mismatches trigger an incident resolved once blocks match again (disabled when empty)")
+	flags.String("pagerduty-dedup", string(qatracker.PagerDutyDedupStream), "Which mismatches share a PagerDuty incident: stream (one incident resolved by the next match) or slot (one per slot, resolved by a later match of that slot)")
+	flags.String("firehose-endpoint", "mainnet.sol.streamingfast.io:443", "StreamingFast Solana Firehose endpoint (default: the --network endpoint)")
+	flags.StringArray("solana-rpc-endpoint", []string{"https://api.mainnet-beta.solana.com"}, "Solana RPC endpoint (default: the --network endpoint), repeat to also compare the Firehose block with additional RPC providers, the first one is primary and gets the artifacts")
+	flags.Int("rpc-quorum", 0, "Number of agreeing --solana-rpc-endpoint providers defining the expected block, Firehose only mismatches when it disagrees with this quorum (0 lets the primary endpoint decide)")
+	flags.String("blocks-store", "", "dstore URL of archived merged blocks (e.g. gs://bucket/merged-blocks) to compare a live source against instead of comparing Firehose with the RPC fetcher")
+	flags.String("blocks-store-against", string(qatracker.BlocksStoreAgainstFirehose), "Live source archived blocks are compared against: firehose or rpc")
+	flags.String("peer-firehose-endpoint", "", "Second Firehose endpoint (e.g. a partner's deployment) every Firehose block is compared with instead of the RPC fetcher block (disabled when empty)")
+	flags.String("peer-firehose-api-token", "", "JWT used to authenticate with --peer-firehose-endpoint, preferably set through QA_PEER_FIREHOSE_API_TOKEN")
+	flags.String("peer-firehose-api-key", "", "API key used to authenticate with --peer-firehose-endpoint when no JWT is set, preferably set through QA_PEER_FIREHOSE_API_KEY")
+	flags.String("geyser-endpoint", "", "Yellowstone Geyser gRPC endpoint (e.g. geyser.example.com:443) whose block subscription every Firehose block is compared with instead of the RPC fetcher block (disabled when empty)")
+	flags.String("geyser-x-token", "", "x-token used to authenticate with --geyser-endpoint, preferably set through QA_GEYSER_X_TOKEN")
+	flags.String("bigtable-project", "", "Google Cloud project of a Solana Bigtable block archive every Firehose block is compared with instead of the RPC fetcher block, for backfills of older slots (disabled when empty)")
+	flags.String("bigtable-instance", "solana-ledger", "Bigtable instance of the Solana block archive in --bigtable-project")
+	flags.String("bigtable-credentials", "", "Service account key file used to read the Bigtable archive (default: the application default credentials)")
+	flags.String("fetcher-config", "", "RPC fetcher options mirroring the Firehose reader deployment as key=value pairs: network, latest-block-retry-interval, commitment, max-supported-transaction-version, rewards")
+	flags.String("network", "mainnet", "Audited network: mainnet, devnet or testnet, selects the fetcher block semantics and the default endpoints")
+	flags.String("commitment", string(rpc.CommitmentConfirmed), "Commitment of the compared head blocks: confirmed or finalized, finalized also makes Firehose stream final blocks only")
+	flags.Uint64("rpc-max-tx-version", 0, "maxSupportedTransactionVersion sent with every RPC getBlock call, overrides the --fetcher-config value")
+	flags.String("firehose-api-token", "", "JWT used to authenticate with Firehose, preferably set through QA_FIREHOSE_API_TOKEN (falls back to FIREHOSE_API_TOKEN)")
+	flags.String("firehose-api-key", "", "API key used to authenticate with Firehose when no JWT is set, preferably set through QA_FIREHOSE_API_KEY (falls back to FIREHOSE_API_KEY)")
+	flags.Bool("compare-finalized", false, "Compare the RPC node's latest finalized slot (getSlot with finalized commitment) instead of the Firehose head")
+	flags.Bool("firehose-wait-for-ready", false, "Wait for the Firehose channel to become ready instead of failing fast on transient unavailability")
+	flags.Duration("firehose-ready-timeout", 30*time.Second, "Maximum time a Firehose fetch waits for the channel when --firehose-wait-for-ready is set")
+	flags.Duration("firehose-fetch-timeout", 0, "Deadline of the Firehose fetch of a comparison (0 disables)")
+	flags.Duration("rpc-fetch-timeout", 0, "Deadline of the RPC fetch of a comparison (0 disables)")
+	flags.Duration("firehose-recv-timeout", 0, "Cancel and reconnect the Firehose stream when no block is received within this duration (0 disables)")
+	flags.Int("firehose-reconnect-attempts", 5, "Rebuild the Firehose connection with exponential backoff up to this many times when a fetch fails with Unavailable or Unauthenticated, re-reading credentials from the environment (0 disables)")
+	flags.Duration("head-stall-timeout", 0, "Alert when the Firehose head slot doesn't advance for this duration (0 disables)")
+	flags.String("artifact-format", "json", "Format of mismatch artifacts: json (protojson) or pb (raw protobuf binary)")
+	flags.String("artifact-scope", "full", "How much of a mismatching block is written: full (entire block) or diff (block header with only the differing transactions)")
+	flags.String("output-dir", ".", "Directory where mismatch artifacts are written")
+	flags.String("output-store", "", "dstore URL mismatch artifacts are written to instead of --output-dir, e.g. s3://bucket/qa or gs://bucket/qa, notifications then show the object URLs")
+	flags.String("output-store-public-url", "", "Public HTTP base URL of the --output-store objects, e.g. https://qa-artifacts.s3.amazonaws.com/mainnet, alerts then link the artifacts under it")
+	flags.Duration("output-store-signed-url-ttl", 0, "Link the artifacts of an s3:// or gs:// --output-store in alerts with URLs signed for this long (0 shows the object URLs)")
+	flags.String("output-prefix", "", "Prefix prepended to all artifact filenames and shown in notifications, e.g. mainnet-")
+	flags.String("checksum-scope", "full", "Projection of the block covered by the comparison checksum: full, header (slot, blockhash, parent, block time, transaction count) or transactions")
+	flags.Int64("blocktime-tolerance", 0, "Maximum BlockTime difference in seconds between both sources not flagged as a mismatch (0 requires an exact match)")
+	flags.String("sanitize-mode", "null", "How sanitized fields (log messages) are cleared: null (absent from JSON artifacts) or empty (present but empty, JSON artifacts then also show every other unset field of the block, e.g. zero fees and empty lists)")
+	flags.StringSlice("ignore-fields", qatracker.DefaultIgnoredFields, "Block field paths stripped from both blocks before checksumming because they legitimately differ by source, e.g. transactions.meta.log_messages,rewards (comma-separated)")
+	flags.String("ignore-fields-file", "", "File listing more block field paths to strip before checksumming, one per line, lines starting with # are skipped")
+	flags.Bool("artifact-proto-names", false, "Use proto snake_case field names instead of camelCase in JSON artifacts")
+	flags.String("skipped-slot-policy", string(qatracker.SkippedSlotPolicyIgnore), "How a slot skipped by one or both sources is classified: ignore (left out of the statistics), match, or mismatch (also notified)")
+	flags.Bool("write-diff-file", false, "On mismatch, also write the differing field paths with both values to diff_<slot>.json")
+	flags.Int("max-differing-transactions", 5, "On mismatch, number of transactions with differing per-transaction checksums whose index and signature are reported in logs and alerts (0 disables)")
+	flags.Int("artifact-queue-size", 0, "Write artifacts from a background queue of this many mismatches, dropping artifacts (but not notifications) when it is full (0 writes synchronously)")
+	flags.Bool("dump-raw-bytes", false, "On mismatch, also write the exact bytes each source delivered, before unmarshal, to .bin files")
+	flags.Duration("alert-cooldown", 0, "Alert the first mismatch immediately and batch the following ones within this window into a single summary message (0 alerts every mismatch)")
+	flags.Bool("notify-on-recovery-only", false, "Only notify when mismatches begin (diverged) and when they stop (healthy), suppressing per-mismatch alerts")
+	flags.Float64("mismatch-rate-threshold", 0, "Alert when mismatches per 100 comparisons over the rolling window reach this value (0 disables)")
+	flags.Int("mismatch-rate-window", 100, "Number of most recent comparisons used to compute the rolling mismatch rate")
+	flags.String("filter-program", "", "Only compare transactions invoking this program ID (base58 public key)")
+	flags.String("digest-schedule", "", "Send an email digest on this schedule: a duration counted from the start of the process (e.g. 24h), or a cron expression in local time (e.g. \"0 9 * * 1-5\", @hourly, @daily), prefixed with CRON_TZ=<zone> for another time zone (empty disables)")
+	flags.String("smtp-host", "", "SMTP server host for the email digest")
+	flags.Int("smtp-port", 587, "SMTP server port for the email digest")
+	flags.String("smtp-username", "", "SMTP username (empty disables authentication)")
+	flags.String("smtp-password", "", "SMTP password")
+	flags.String("smtp-from", "", "Sender address of the email digest")
+	flags.StringSlice("smtp-to", nil, "Recipient addresses of the email digest (comma-separated)")
+	flags.Duration("stats-flush-interval", 0, "Persist the aggregate comparison stats to --stats-file at this interval (0 disables)")
+	flags.String("stats-file", "stats.json", "JSON file the aggregate comparison stats are checkpointed to")
+	flags.Bool("stats-resume", false, "Load the stats of a previous checkpoint from --stats-file on startup and continue from them")
+	flags.Bool("normalize-order", false, "Ignore transaction ordering when comparing checksums (ordering differences are still logged)")
+	flags.Bool("check-linkage", false, "Verify within each source that every compared block's previous blockhash is the blockhash of the previously compared block")
+	flags.Int("max-concurrent-comparisons", 1, "Maximum number of comparisons running simultaneously, whichever code path triggers them")
+	flags.Int("rpc-batch-size", 0, "In batch and range modes, fetch RPC blocks in JSON-RPC batches of this many slots, falling back to individual requests if the provider rejects batches (0 or 1 disables)")
+	flags.Int("prefetch-depth", 0, "In batch and range modes, fetch the RPC fetcher blocks of up to this many upcoming slots while the current one is compared, using spare comparison slots (0 disables)")
+	flags.Int("concurrency", 1, "In range and follow modes, number of blocks of the Firehose stream compared in parallel by a pool of workers, each taking a comparison slot")
+	flags.Uint64("min-free-memory", 0, "Defer a comparison to the next tick when available system memory is below this many MiB (0 disables)")
+	flags.Duration("compare-rate-window", 0, "Restart the SLA match rate counters recorded in the stats checkpoint at this interval, e.g. 720h (0 never restarts them)")
+	flags.Bool("telemetry", false, "Opt in to periodically reporting anonymized aggregate stats (no block contents, slots or endpoint URLs) to --telemetry-url")
+	flags.String("telemetry-url", "", "Collector URL receiving the anonymized telemetry reports")
+	flags.Duration("telemetry-interval", 24*time.Hour, "Interval between anonymized telemetry reports")
+	flags.String("results-log", "", "File every comparison result, matches included, is appended to as a JSON line with both checksums and fetch durations (disabled when empty)")
+	flags.String("results-postgres", "", "Postgres DSN where every comparison result is stored, the results table is created on first connect (empty disables)")
+	flags.String("results-sqlite", "", "SQLite database file where every comparison result is stored, created with its results table when missing (empty disables)")
+	flags.String("grpc-listen-addr", "", "In interval, follow and serve modes, serve the sf.solana.qa.v1.Tracker gRPC service (Compare, Watch) on this address, e.g. :9000 (disabled when empty)")
+	flags.Uint64("seed", 0, "Seed of all randomness in the tracker (sampling, jitter), a seed is generated and logged when unset")
+	flags.Bool("decoder-check", false, "Compare the regular decoder against a reflection-based decoder on the same Firehose bytes instead of comparing against RPC")
+}
+
+// endpointConfig holds the fetcher settings and the endpoints of the selected network
+type endpointConfig struct {
+	Fetcher           qatracker.FetcherConfig
+	FirehoseEndpoint  string
+	SolanaRPCEndpoint string
+	RPCProviders      []string
+}
+
+// parseEndpointFlags resolves the fetcher settings and the endpoints from the network related
+// flags, an endpoint not set explicitly defaults to the one of the network. Nothing is dialed.
+func parseEndpointFlags(cmd *cobra.Command) (endpointConfig, error) {
+	fetcherConfigValue, _ := cmd.Flags().GetString("fetcher-config")
+	fetcherConfig, err := qatracker.ParseFetcherConfig(fetcherConfigValue)
+	if err != nil {
+		return endpointConfig{}, err
+	}
+	if cmd.Flags().Changed("network") {
+		networkValue, _ := cmd.Flags().GetString("network")
+		if fetcherConfig.Network, err = qatracker.ParseNetwork(networkValue); err != nil {
+			return endpointConfig{}, err
+		}
+	}
+	firehoseEndpoint, err := networkEndpoint(cmd, "firehose-endpoint", fetcherConfig.Network, qatracker.DefaultNetworkEndpoints[fetcherConfig.Network].Firehose)
+	if err != nil {
+		return endpointConfig{}, err
+	}
+	solanaRPCEndpoint, rpcProviders, err := rpcEndpoints(cmd, fetcherConfig.Network)
+	if err != nil {
+		return endpointConfig{}, err
+	}
+	if cmd.Flags().Changed("commitment") {
+		commitmentValue, _ := cmd.Flags().GetString("commitment")
+		if fetcherConfig.Commitment, err = qatracker.ParseCommitment(commitmentValue); err != nil {
+			return endpointConfig{}, err
+		}
+	}
+	if cmd.Flags().Changed("rpc-max-tx-version") {
+		fetcherConfig.MaxSupportedTransactionVersion, _ = cmd.Flags().GetUint64("rpc-max-tx-version")
+	}
+
+	return endpointConfig{Fetcher: fetcherConfig, FirehoseEndpoint: firehoseEndpoint, SolanaRPCEndpoint: solanaRPCEndpoint, RPCProviders: rpcProviders}, nil
+}
+
+// ignoredFieldsFromFlags resolves the field paths of --ignore-fields and --ignore-fields-file,
+// the paths of the file are added to the flag ones
+func ignoredFieldsFromFlags(cmd *cobra.Command) (qatracker.IgnoredFields, error) {
+	paths, _ := cmd.Flags().GetStringSlice("ignore-fields")
+	if file, _ := cmd.Flags().GetString("ignore-fields-file"); file != "" {
+		filePaths, err := qatracker.ReadIgnoredFieldsFile(file)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, filePaths...)
+	}
+	return qatracker.ParseIgnoredFields(paths)
+}
+
+// networkEndpoint returns the endpoint of the given flag, defaulting to the network endpoint
+// when not set explicitly. An explicit endpoint naming another network is kept with a warning.
+func networkEndpoint(cmd *cobra.Command, flag, network, networkDefault string) (string, error) {
+	if !cmd.Flags().Changed(flag) {
+		if networkDefault == "" {
+			return "", fmt.Errorf("--%s has no default for network %s and must be set explicitly", flag, network)
+		}
+		return networkDefault, nil
+	}
+
+	endpoint, _ := cmd.Flags().GetString(flag)
+	warnEndpointNetwork(flag, endpoint, network)
+	return endpoint, nil
+}
+
+// rpcEndpoints returns the primary Solana RPC endpoint, the first --solana-rpc-endpoint, and the
+// additional RPC providers the Firehose block is also compared with
+func rpcEndpoints(cmd *cobra.Command, network string) (string, []string, error) {
+	const flag = "solana-rpc-endpoint"
+	if !cmd.Flags().Changed(flag) {
+		endpoint, err := networkEndpoint(cmd, flag, network, qatracker.DefaultNetworkEndpoints[network].RPC)
+		return endpoint, nil, err
+	}
+
+	endpoints, _ := cmd.Flags().GetStringArray(flag)
+	for _, endpoint := range endpoints {
+		warnEndpointNetwork(flag, endpoint, network)
+	}
+	return endpoints[0], endpoints[1:], nil
+}
+
+// warnEndpointNetwork warns when an endpoint names another network than the audited one
+func warnEndpointNetwork(flag, endpoint, network string) {
+	if other := qatracker.EndpointNetwork(endpoint); other != "" && other != network {
+		zlog.Warn("Endpoint names a different network than the audited one",
+			zap.String("flag", flag),
+			zap.String("endpoint", endpoint),
+			zap.String("network", network),
+			zap.String("endpoint_network", other))
+	}
 }
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// defaultRootConfig is the rootConfig of a 30s interval without any flag or environment variable
+var defaultRootConfig = rootConfig{
+	Interval:         30 * time.Second,
+	ShutdownTimeout:  30 * time.Second,
+	monitoringConfig: monitoringConfig{HeartbeatInterval: time.Minute, HealthStaleness: 5 * time.Minute},
+}
+
+func TestParseRootArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    func(config *rootConfig)
+		wantErr string
+	}{
+		{name: "defaults", args: []string{"30s"}},
+		{
+			name: "flags",
+			args: []string{"30s", "--batch=5", "--shutdown-timeout=1m", "--metrics-listen-addr=:9102", "--state-file=state.json"},
+			want: func(config *rootConfig) {
+				config.Batch, config.ShutdownTimeout, config.MetricsListenAddr, config.StateFile = 5, time.Minute, ":9102", "state.json"
+			},
+		},
+		{
+			name: "environment variables",
+			args: []string{"30s"},
+			env:  map[string]string{"QA_BATCH": "10", "QA_HEALTH_LISTEN_ADDR": ":8080", "QA_HEALTH_STALENESS": "2m"},
+			want: func(config *rootConfig) {
+				config.Batch, config.HealthListenAddr, config.HealthStaleness = 10, ":8080", 2*time.Minute
+			},
+		},
+		{
+			name: "flag takes precedence over environment variable",
+			args: []string{"30s", "--batch=5", "--metrics-listen-addr=:9102"},
+			env:  map[string]string{"QA_BATCH": "10", "QA_METRICS_LISTEN_ADDR": ":9999", "QA_MAX_CONSECUTIVE_ERRORS": "3"},
+			want: func(config *rootConfig) {
+				config.Batch, config.MetricsListenAddr, config.MaxConsecutiveErrors = 5, ":9102", 3
+			},
+		},
+		{
+			name:    "invalid interval",
+			args:    []string{"soon"},
+			wantErr: "invalid interval format",
+		},
+		{
+			name:    "interval below the minimum",
+			args:    []string{"500ms"},
+			wantErr: "below the minimum allowed interval",
+		},
+		{
+			name: "minimum interval lowered by environment variable",
+			args: []string{"500ms"},
+			env:  map[string]string{"QA_MIN_INTERVAL": "100ms"},
+			want: func(config *rootConfig) {
+				config.Interval = 500 * time.Millisecond
+			},
+		},
+		{
+			name:    "minimum interval flag takes precedence over environment variable",
+			args:    []string{"500ms", "--min-interval=1s"},
+			env:     map[string]string{"QA_MIN_INTERVAL": "100ms"},
+			wantErr: "below the minimum allowed interval",
+		},
+		{
+			name:    "invalid flag value",
+			args:    []string{"30s", "--heartbeat-interval=often"},
+			wantErr: "invalid argument \"often\"",
+		},
+		{
+			name:    "invalid environment variable value",
+			args:    []string{"30s"},
+			env:     map[string]string{"QA_BATCH": "many"},
+			wantErr: "invalid value \"many\" in QA_BATCH for --batch",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			config, err := parseRootTestArgs(test.args)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := defaultRootConfig
+			if test.want != nil {
+				test.want(&want)
+			}
+			if config != want {
+				t.Fatalf("unexpected config\n got: %+v\nwant: %+v", config, want)
+			}
+		})
+	}
+}
+
+// newTestCmd parses args into a fresh command holding the root and persistent flags like the
+// root command does, flags first, then the environment variables of the flags not set
+func newTestCmd(args []string) (*cobra.Command, error) {
+	cmd := &cobra.Command{Use: RootCmd.Use}
+	addRootFlags(cmd.Flags())
+	addPersistentFlags(cmd.PersistentFlags())
+	if err := cmd.ParseFlags(args); err != nil {
+		return nil, err
+	}
+	return cmd, bindFlagsToEnv(cmd)
+}
+
+// parseRootTestArgs parses args like the root command does, up to the root arguments
+func parseRootTestArgs(args []string) (rootConfig, error) {
+	cmd, err := newTestCmd(args)
+	if err != nil {
+		return rootConfig{}, err
+	}
+	return parseRootArgs(cmd, cmd.Flags().Args())
+}
+
+func TestParseEndpointFlags(t *testing.T) {
+	mainnet := endpointConfig{
+		Fetcher:           qatracker.DefaultFetcherConfig(),
+		FirehoseEndpoint:  qatracker.DefaultNetworkEndpoints["mainnet"].Firehose,
+		SolanaRPCEndpoint: qatracker.DefaultNetworkEndpoints["mainnet"].RPC,
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    func(config *endpointConfig)
+		wantErr string
+	}{
+		{name: "defaults"},
+		{
+			name: "firehose endpoint from environment variable",
+			env:  map[string]string{"QA_FIREHOSE_ENDPOINT": "firehose.example.com:443"},
+			want: func(config *endpointConfig) {
+				config.FirehoseEndpoint = "firehose.example.com:443"
+			},
+		},
+		{
+			name: "solana rpc endpoint from environment variable",
+			env:  map[string]string{"QA_SOLANA_RPC_ENDPOINT": "https://rpc.example.com"},
+			want: func(config *endpointConfig) {
+				config.SolanaRPCEndpoint, config.RPCProviders = "https://rpc.example.com", []string{}
+			},
+		},
+		{
+			name: "network from environment variable",
+			env:  map[string]string{"QA_NETWORK": "devnet", "QA_FIREHOSE_ENDPOINT": "firehose.example.com:443", "QA_SOLANA_RPC_ENDPOINT": "https://rpc.example.com"},
+			want: func(config *endpointConfig) {
+				config.Fetcher.Network = "devnet"
+				config.FirehoseEndpoint, config.SolanaRPCEndpoint, config.RPCProviders = "firehose.example.com:443", "https://rpc.example.com", []string{}
+			},
+		},
+		{
+			name: "network from environment variable selects its default endpoints",
+			env:  map[string]string{"QA_NETWORK": "devnet"},
+			want: func(config *endpointConfig) {
+				config.Fetcher.Network = "devnet"
+				config.FirehoseEndpoint = qatracker.DefaultNetworkEndpoints["devnet"].Firehose
+				config.SolanaRPCEndpoint = qatracker.DefaultNetworkEndpoints["devnet"].RPC
+			},
+		},
+		{
+			name:    "network from environment variable without a default firehose endpoint",
+			env:     map[string]string{"QA_NETWORK": "testnet"},
+			wantErr: "--firehose-endpoint has no default for network testnet",
+		},
+		{
+			name: "commitment from environment variable",
+			env:  map[string]string{"QA_COMMITMENT": "finalized"},
+			want: func(config *endpointConfig) {
+				config.Fetcher.Commitment = rpc.CommitmentFinalized
+			},
+		},
+		{
+			name: "flags take precedence over environment variables",
+			args: []string{"--commitment=confirmed", "--firehose-endpoint=flag.example.com:443"},
+			env:  map[string]string{"QA_COMMITMENT": "finalized", "QA_FIREHOSE_ENDPOINT": "env.example.com:443"},
+			want: func(config *endpointConfig) {
+				config.FirehoseEndpoint = "flag.example.com:443"
+			},
+		},
+		{
+			name:    "invalid network from environment variable",
+			env:     map[string]string{"QA_NETWORK": "moonnet"},
+			wantErr: "invalid network \"moonnet\"",
+		},
+		{
+			name:    "invalid commitment from environment variable",
+			env:     map[string]string{"QA_COMMITMENT": "processed"},
+			wantErr: "invalid commitment \"processed\"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			cmd, err := newTestCmd(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			config, err := parseEndpointFlags(cmd)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := mainnet
+			if test.want != nil {
+				test.want(&want)
+			}
+			if !reflect.DeepEqual(config, want) {
+				t.Fatalf("unexpected config\n got: %+v\nwant: %+v", config, want)
+			}
+		})
+	}
+}
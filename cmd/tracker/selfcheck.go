@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// SelfCheckCmd fetches the same slot twice from one source and asserts both checksums are identical
+var SelfCheckCmd = &cobra.Command{
+	Use:   "selfcheck",
+	Short: "Fetch a block twice from the same source and check both fetches are identical",
+	Long: `Fetches the block at --slot twice from --source and asserts both checksums are identical.
+A source that isn't deterministic on its own (e.g. unordered maps in its encoder) produces
+mismatches that have nothing to do with the other source, run this before trusting
+cross-source mismatches:
+
+  solana-block-qa-tracker selfcheck --source firehose --slot 250000000
+
+A self-inconsistency writes both fetches to artifact files, sends a Slack alert and exits non-zero.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceValue, _ := cmd.Flags().GetString("source")
+		slot, _ := cmd.Flags().GetUint64("slot")
+
+		source, err := qatracker.ParseSelfCheckSource(sourceValue)
+		if err != nil {
+			return err
+		}
+		if slot == 0 {
+			return fmt.Errorf("--slot is required")
+		}
+
+		tracker, err := newTrackerFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		defer tracker.CloseLogged()
+
+		err = tracker.SelfCheck(cmd.Context(), source, slot)
+		tracker.FlushNotifications()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Slot %d: %s is deterministic across two fetches\n", slot, source)
+		return nil
+	},
+}
+
+func init() {
+	SelfCheckCmd.Flags().String("source", string(qatracker.SelfCheckSourceFirehose), "Source fetched twice: firehose or rpc")
+	SelfCheckCmd.Flags().Uint64("slot", 0, "Slot to fetch twice (required)")
+}
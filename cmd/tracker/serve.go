@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd exposes on-demand comparisons over a REST API
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a REST API running comparisons on demand",
+	Long: `Serves a REST API on --listen-addr so other tools can trigger QA checks. No ticker is
+started, comparisons only run on request and their results are published like in the other
+modes (notifications, results store, metrics...):
+
+  POST /compare/{slot}  compares the block at slot (0 for the Firehose head block) and answers
+                        with the comparison result, 429 when all comparison slots are in use
+  GET  /status          answers with the result of the last comparison
+  GET  /history         answers with the last --history-size results, most recent first
+                        (?limit=N keeps fewer)
+
+  solana-block-qa-tracker serve --listen-addr :8090
+  curl -X POST localhost:8090/compare/250000000 | jq .match
+
+Runs until interrupted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listenAddr, _ := cmd.Flags().GetString("listen-addr")
+		historySize, _ := cmd.Flags().GetInt("history-size")
+
+		tracker, err := newTrackerFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		defer tracker.CloseLogged()
+
+		err = tracker.Serve(cmd.Context(), listenAddr, historySize)
+		tracker.FlushNotifications()
+		return err
+	},
+}
+
+func init() {
+	ServeCmd.Flags().String("listen-addr", ":8090", "Address the REST API listens on")
+	ServeCmd.Flags().Int("history-size", 100, "Number of comparison results kept for GET /history")
+}
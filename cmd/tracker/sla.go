@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// SLACmd reports the match rate persisted in the stats checkpoint file
+var SLACmd = &cobra.Command{
+	Use:   "sla",
+	Short: "Report the slot match rate recorded in the stats checkpoint",
+	Long: `Reads --stats-file and prints the percentage of sampled slots that matched over the
+current match rate window. With --reset, the window is restarted in the file; stop the tracker
+first, otherwise its next checkpoint overwrites the reset.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statsFile, _ := cmd.Flags().GetString("stats-file")
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		stats, err := qatracker.ReadStatsCheckpoint(statsFile)
+		if err != nil {
+			return err
+		}
+
+		counter := stats.MatchRate
+		fmt.Fprintf(cmd.OutOrStdout(), "%.2f%% of sampled slots matched since %s (%d of %d)\n",
+			counter.Rate(), counter.Since.Format(time.RFC3339), counter.Matched, counter.Total)
+
+		if !reset {
+			return nil
+		}
+
+		if err := qatracker.ResetStatsMatchRate(statsFile, time.Now()); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Match rate window reset\n")
+		return nil
+	},
+}
+
+func init() {
+	SLACmd.Flags().Bool("reset", false, "Restart the match rate window after reporting it")
+}
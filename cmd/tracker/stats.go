@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// StatsCmd summarizes the results history over a time window
+var StatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the comparison results stored over a time window",
+	Long: `Reads the results history of --results-sqlite, or of --results-log, and prints the match
+and mismatch rates, the mean fetch latency of each source and the most recent mismatches over the
+last --window:
+
+  solana-block-qa-tracker stats --results-sqlite results.db --window 168h
+
+With --results-sqlite, only the results tagged with --output-prefix are counted when it is set.
+Nothing is fetched, the command only reads the history.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		window, _ := cmd.Flags().GetDuration("window")
+		recent, _ := cmd.Flags().GetInt("recent")
+		resultsSQLite, _ := cmd.Flags().GetString("results-sqlite")
+		resultsLogPath, _ := cmd.Flags().GetString("results-log")
+		outputPrefix, _ := cmd.Flags().GetString("output-prefix")
+
+		if window <= 0 {
+			return fmt.Errorf("--window must be positive")
+		}
+		since := time.Now().Add(-window)
+
+		var stats qatracker.ResultsStats
+		var source string
+		var err error
+		switch {
+		case resultsSQLite != "":
+			source = resultsSQLite
+			stats, err = qatracker.SQLiteResultsStats(cmd.Context(), resultsSQLite, outputPrefix, since, recent)
+		case resultsLogPath != "":
+			source = resultsLogPath
+			stats, err = qatracker.ResultsLogStats(resultsLogPath, since, recent)
+		default:
+			return fmt.Errorf("--results-sqlite or --results-log is required")
+		}
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Results of the last %s (since %s) from %s:\n", window, stats.Since.Format(time.RFC3339), source)
+		fmt.Fprintf(out, "  Compared:     %d (%d skipped)\n", stats.Compared, stats.Skipped)
+		fmt.Fprintf(out, "  Matched:      %d (%.2f%%)\n", stats.Matched, stats.MatchRate())
+		fmt.Fprintf(out, "  Mismatched:   %d (%.2f%%)\n", stats.Mismatched(), 100-stats.MatchRate())
+		fmt.Fprintf(out, "  Mean Firehose fetch:    %s\n", formatMeanFetch(stats.MeanFirehoseFetch))
+		fmt.Fprintf(out, "  Mean RPC Fetcher fetch: %s\n", formatMeanFetch(stats.MeanRPCFetcherFetch))
+
+		if len(stats.RecentMismatches) == 0 {
+			return nil
+		}
+		fmt.Fprintf(out, "Most recent mismatches:\n")
+		for _, result := range stats.RecentMismatches {
+			categories := strings.Join(result.DiffCategories, ", ")
+			if result.Skipped {
+				categories = "skipped by one source"
+			}
+			fmt.Fprintf(out, "  %d  %s  %s\n", result.Slot, result.Time.Format(time.RFC3339), categories)
+		}
+		return nil
+	},
+}
+
+// formatMeanFetch formats a mean fetch latency, which is unknown when no fetch was measured
+func formatMeanFetch(mean time.Duration) string {
+	if mean == 0 {
+		return "n/a"
+	}
+	return mean.Round(time.Millisecond).String()
+}
+
+func init() {
+	StatsCmd.Flags().Duration("window", 24*time.Hour, "Time window of the summarized results, ending now")
+	StatsCmd.Flags().Int("recent", 10, "Number of most recent mismatches listed")
+}
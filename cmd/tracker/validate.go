@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"solana-block-qa-tracker/pkg/qatracker"
+)
+
+// exclusiveFlags are pairs of flags that contradict each other, one would silently override or
+// cancel the other
+var exclusiveFlags = [][2]string{
+	{"decoder-check", "batch"},
+	{"decoder-check", "compare-finalized"},
+	{"decoder-check", "blocks-store"},
+	{"decoder-check", "peer-firehose-endpoint"},
+	{"blocks-store", "peer-firehose-endpoint"},
+	{"decoder-check", "geyser-endpoint"},
+	{"blocks-store", "geyser-endpoint"},
+	{"peer-firehose-endpoint", "geyser-endpoint"},
+	{"decoder-check", "bigtable-project"},
+	{"blocks-store", "bigtable-project"},
+	{"peer-firehose-endpoint", "bigtable-project"},
+	{"geyser-endpoint", "bigtable-project"},
+	{"batch", "compare-finalized"},
+	{"firehose-api-token", "firehose-api-key"},
+	{"peer-firehose-api-token", "peer-firehose-api-key"},
+	{"output-dir", "output-store"},
+	{"output-store-public-url", "output-store-signed-url-ttl"},
+	{"results-postgres", "results-sqlite"},
+	{"alert-cooldown", "notify-on-recovery-only"},
+}
+
+// dependentFlags maps flags to the flag they have no effect without
+var dependentFlags = []struct {
+	flag     string
+	requires string
+}{
+	{"blocks-store-against", "blocks-store"},
+	{"peer-firehose-api-token", "peer-firehose-endpoint"},
+	{"peer-firehose-api-key", "peer-firehose-endpoint"},
+	{"geyser-x-token", "geyser-endpoint"},
+	{"bigtable-instance", "bigtable-project"},
+	{"bigtable-credentials", "bigtable-project"},
+	{"firehose-ready-timeout", "firehose-wait-for-ready"},
+	{"mismatch-rate-window", "mismatch-rate-threshold"},
+	{"smtp-host", "digest-schedule"},
+	{"smtp-port", "digest-schedule"},
+	{"smtp-username", "digest-schedule"},
+	{"smtp-password", "digest-schedule"},
+	{"smtp-from", "digest-schedule"},
+	{"smtp-to", "digest-schedule"},
+	{"stats-file", "stats-flush-interval"},
+	{"stats-resume", "stats-flush-interval"},
+	{"compare-rate-window", "stats-flush-interval"},
+	{"telemetry-url", "telemetry"},
+	{"pagerduty-dedup", "pagerduty-routing-key"},
+	{"notify-webhook-template", "notify-webhook-url"},
+	{"output-store-public-url", "output-store"},
+	{"output-store-signed-url-ttl", "output-store"},
+	{"telemetry-interval", "telemetry"},
+	{"health-staleness", "health-listen-addr"},
+}
+
+// validateFlags rejects mutually exclusive or nonsensical flag combinations with a precise
+// message instead of letting flags quietly override each other. Only flags explicitly set are
+// considered: on the command line, through their QA_* environment variable or in the config file,
+// which all mark the flag as changed.
+func validateFlags(flags *pflag.FlagSet) error {
+	changed := func(name string) bool {
+		flag := flags.Lookup(name)
+		return flag != nil && flag.Changed
+	}
+
+	for _, pair := range exclusiveFlags {
+		if changed(pair[0]) && changed(pair[1]) {
+			return fmt.Errorf("--%s and --%s cannot be used together", pair[0], pair[1])
+		}
+	}
+
+	for _, dependent := range dependentFlags {
+		if changed(dependent.flag) && !changed(dependent.requires) {
+			return fmt.Errorf("--%s has no effect without --%s", dependent.flag, dependent.requires)
+		}
+	}
+
+	if changed("network") {
+		if fetcherConfig, _ := flags.GetString("fetcher-config"); qatracker.HasFetcherConfigKey(fetcherConfig, "network") {
+			return fmt.Errorf("--network and the network key of --fetcher-config cannot be used together, set the network with --network only")
+		}
+	}
+
+	if changed("commitment") {
+		if fetcherConfig, _ := flags.GetString("fetcher-config"); qatracker.HasFetcherConfigKey(fetcherConfig, "commitment") {
+			return fmt.Errorf("--commitment and the commitment key of --fetcher-config cannot be used together, set the commitment with --commitment only")
+		}
+	}
+
+	if changed("artifact-proto-names") {
+		if format, _ := flags.GetString("artifact-format"); format == string(qatracker.ArtifactFormatPB) {
+			return fmt.Errorf("--artifact-proto-names only applies to JSON artifacts and cannot be used with --artifact-format pb")
+		}
+	}
+
+	if prefetchDepth, _ := flags.GetInt("prefetch-depth"); prefetchDepth > 0 {
+		if maxConcurrent, _ := flags.GetInt("max-concurrent-comparisons"); maxConcurrent <= 1 {
+			return fmt.Errorf("--prefetch-depth needs spare comparison slots, set --max-concurrent-comparisons above 1")
+		}
+	}
+
+	if concurrency, _ := flags.GetInt("concurrency"); concurrency > 1 {
+		if maxConcurrent, _ := flags.GetInt("max-concurrent-comparisons"); maxConcurrent < concurrency {
+			return fmt.Errorf("--concurrency %d needs a comparison slot per worker, set --max-concurrent-comparisons to at least %d", concurrency, concurrency)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "no flags"},
+		{
+			name:    "exclusive flags on the command line",
+			args:    []string{"--firehose-api-token=token", "--firehose-api-key=key"},
+			wantErr: "--firehose-api-token and --firehose-api-key cannot be used together",
+		},
+		{
+			name:    "exclusive flags through environment variables",
+			env:     map[string]string{"QA_FIREHOSE_API_TOKEN": "token", "QA_FIREHOSE_API_KEY": "key"},
+			wantErr: "--firehose-api-token and --firehose-api-key cannot be used together",
+		},
+		{
+			name:    "exclusive flags from the command line and an environment variable",
+			args:    []string{"--decoder-check"},
+			env:     map[string]string{"QA_BATCH": "10"},
+			wantErr: "--decoder-check and --batch cannot be used together",
+		},
+		{
+			name:    "dependent flag through an environment variable",
+			env:     map[string]string{"QA_HEALTH_STALENESS": "1m"},
+			wantErr: "--health-staleness has no effect without --health-listen-addr",
+		},
+		{
+			name: "dependent flags through environment variables",
+			env:  map[string]string{"QA_HEALTH_STALENESS": "1m", "QA_HEALTH_LISTEN_ADDR": ":8080"},
+		},
+		{
+			name:    "network through an environment variable and in the fetcher config",
+			args:    []string{"--fetcher-config=network=devnet"},
+			env:     map[string]string{"QA_NETWORK": "devnet"},
+			wantErr: "--network and the network key of --fetcher-config cannot be used together",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			cmd, err := newTestCmd(test.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = validateFlags(cmd.Flags())
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package qatracker
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// alertCooldown limits mismatch alerts to one per cooldown window, the mismatches suppressed
+// within a window are summarized in a single message once it ends
+type alertCooldown struct {
+	cooldown time.Duration
+
+	mu         sync.Mutex
+	lastAlert  time.Time
+	suppressed []uint64
+	timer      *time.Timer
+}
+
+// WithAlertCooldown coalesces mismatch alerts: the first mismatch alerts immediately, the
+// following ones within cooldown are batched into one summary message sent when it elapses
+func WithAlertCooldown(cooldown time.Duration) Option {
+	return func(t *Tracker) {
+		t.alertCooldown = &alertCooldown{cooldown: cooldown}
+	}
+}
+
+// admitAlert reports whether the mismatch alert of slot is sent now. Otherwise the slot is kept
+// for the summary sent at the end of the cooldown window. The last alert is never more recent
+// than the last mismatch, so a clean period as long as the cooldown always resets the state and
+// the next divergence alerts immediately.
+func (t *Tracker) admitAlert(slot uint64) bool {
+	c := t.alertCooldown
+	if c == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastAlert) >= c.cooldown {
+		c.lastAlert = now
+		return true
+	}
+
+	c.suppressed = append(c.suppressed, slot)
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.lastAlert.Add(c.cooldown).Sub(now), t.sendAlertSummary)
+	}
+	t.logger.Debug("Mismatch alert coalesced", zap.Uint64("slot", slot), zap.Int("suppressed", len(c.suppressed)))
+	return false
+}
+
+// sendAlertSummary sends one message summarizing the mismatches suppressed in the current
+// cooldown window, if any, and starts a new window
+func (t *Tracker) sendAlertSummary() {
+	c := t.alertCooldown
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	slots := c.suppressed
+	c.suppressed = nil
+	if len(slots) > 0 {
+		c.lastAlert = time.Now()
+	}
+	c.mu.Unlock()
+
+	if len(slots) == 0 {
+		return
+	}
+
+	first, last := slices.Min(slots), slices.Max(slots)
+	t.logger.Warn("Coalesced mismatches", zap.Int("slots", len(slots)), zap.Uint64("first_slot", first), zap.Uint64("last_slot", last))
+	message := fmt.Sprintf("🚨 *Solana Block QA Alert Summary* 🚨\n"+
+		"%s"+
+		"%d more slots diverged between %d and %d within the %s alert cooldown\n"+
+		"• Time: %s",
+		t.environmentLine(), len(slots), first, last, c.cooldown, time.Now().Format("2006-01-02 15:04:05"))
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send alert summary Slack notification", zap.Error(err))
+	}
+}
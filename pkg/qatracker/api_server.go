@@ -0,0 +1,169 @@
+package qatracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// apiShutdownTimeout bounds how long the API server waits for in-flight requests on shutdown
+const apiShutdownTimeout = 10 * time.Second
+
+// apiServer serves the REST API of serve mode, it keeps the results of the comparisons it ran
+type apiServer struct {
+	tracker *Tracker
+
+	mu      sync.Mutex
+	size    int
+	history []Result // Most recent last
+}
+
+// apiError is the body of an API error response
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// Serve serves the REST API on listenAddr, e.g. ":8090", until ctx is cancelled:
+//
+//   - POST /compare/{slot} compares the block at slot (the Firehose head block for 0) and answers
+//     with its result, 429 when all comparison slots are in use
+//   - GET /status answers with the result of the last comparison, 404 before the first one
+//   - GET /history answers with the results of the last historySize comparisons, most recent
+//     first, ?limit= keeps fewer
+//
+// Comparisons are only run on request, their results are published like in the other modes.
+func (t *Tracker) Serve(ctx context.Context, listenAddr string, historySize int) error {
+	if err := t.validateFetcherConfig(ctx); err != nil {
+		return err
+	}
+	stopGRPCServer, err := t.startGRPCServer()
+	if err != nil {
+		return err
+	}
+	defer stopGRPCServer()
+
+	api := &apiServer{tracker: t, size: max(historySize, 1)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /compare/{slot}", api.serveCompare)
+	mux.HandleFunc("GET /status", api.serveStatus)
+	mux.HandleFunc("GET /history", api.serveHistory)
+
+	// In-flight comparisons are aborted by a shutdown signal, like in the other modes
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for API requests on %s: %w", listenAddr, err)
+	}
+	t.logger.Info("Serving QA API", zap.String("listen_addr", listener.Addr().String()))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("API server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	t.logger.Info("Shutting down QA API")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), apiShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		t.logger.Warn("Failed to shut down API server", zap.Error(err))
+	}
+	return nil
+}
+
+// serveCompare runs an on-demand comparison. A comparison slot is taken without waiting, so
+// callers are told to retry instead of piling up requests holding blocks in memory.
+func (a *apiServer) serveCompare(w http.ResponseWriter, r *http.Request) {
+	slot, err := strconv.ParseUint(r.PathValue("slot"), 10, 64)
+	if err != nil {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid slot %q", r.PathValue("slot"))})
+		return
+	}
+
+	if !a.tracker.tryAcquireComparison() {
+		writeAPIJSON(w, http.StatusTooManyRequests, apiError{Error: "all comparison slots are in use, retry later"})
+		return
+	}
+	result, err := a.tracker.CompareSlot(r.Context(), slot)
+	a.tracker.releaseComparison()
+	if err != nil {
+		a.tracker.logger.Warn("On-demand comparison failed", zap.Uint64("slot", slot), zap.Error(err))
+		writeAPIJSON(w, http.StatusBadGateway, apiError{Error: err.Error()})
+		return
+	}
+
+	a.record(*result)
+	writeAPIJSON(w, http.StatusOK, result)
+}
+
+// serveStatus answers with the last comparison result
+func (a *apiServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	results := a.recent(1)
+	if len(results) == 0 {
+		writeAPIJSON(w, http.StatusNotFound, apiError{Error: "no comparison ran yet"})
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, results[0])
+}
+
+// serveHistory answers with the recent comparison results, most recent first
+func (a *apiServer) serveHistory(w http.ResponseWriter, r *http.Request) {
+	limit := a.size
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid limit %q", value)})
+			return
+		}
+		limit = parsed
+	}
+	writeAPIJSON(w, http.StatusOK, a.recent(limit))
+}
+
+// record adds a result to the history, dropping the oldest one when full
+func (a *apiServer) record(result Result) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.history) == a.size {
+		a.history = a.history[1:]
+	}
+	a.history = append(a.history, result)
+}
+
+// recent returns up to limit results of the history, most recent first
+func (a *apiServer) recent(limit int) []Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	results := make([]Result, 0, min(limit, len(a.history)))
+	for i := len(a.history) - 1; i >= 0 && len(results) < limit; i-- {
+		results = append(results, a.history[i])
+	}
+	return results
+}
+
+// writeAPIJSON writes body as the JSON response with status
+func writeAPIJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
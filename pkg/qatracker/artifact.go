@@ -0,0 +1,204 @@
+package qatracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ArtifactFormat selects how mismatching blocks are dumped to disk
+type ArtifactFormat string
+
+const (
+	// ArtifactFormatJSON writes blocks as indented protojson (human readable)
+	ArtifactFormatJSON ArtifactFormat = "json"
+	// ArtifactFormatPB writes blocks as raw proto.Marshal binary (compact and exact)
+	ArtifactFormatPB ArtifactFormat = "pb"
+)
+
+// ParseArtifactFormat validates and returns the artifact format for the given value
+func ParseArtifactFormat(value string) (ArtifactFormat, error) {
+	switch format := ArtifactFormat(strings.ToLower(value)); format {
+	case ArtifactFormatJSON, ArtifactFormatPB:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid artifact format %q (valid values: json, pb)", value)
+	}
+}
+
+// ArtifactScope selects how much of a mismatching block is dumped to disk
+type ArtifactScope string
+
+const (
+	// ArtifactScopeFull writes the entire block
+	ArtifactScopeFull ArtifactScope = "full"
+	// ArtifactScopeDiff writes the block header with only the differing transactions
+	ArtifactScopeDiff ArtifactScope = "diff"
+)
+
+// ParseArtifactScope validates and returns the artifact scope for the given value
+func ParseArtifactScope(value string) (ArtifactScope, error) {
+	switch scope := ArtifactScope(strings.ToLower(value)); scope {
+	case ArtifactScopeFull, ArtifactScopeDiff:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid artifact scope %q (valid values: full, diff)", value)
+	}
+}
+
+// WithArtifactScope sets how much of a mismatching block is written to artifacts
+func WithArtifactScope(scope ArtifactScope) Option {
+	return func(t *Tracker) {
+		t.artifactScope = scope
+	}
+}
+
+// trimBlockToDiffs returns a copy of the block header holding only the transactions that have
+// a field diff, the original block is left untouched
+func trimBlockToDiffs(block *pbsol.Block, diffs []FieldDiff) *pbsol.Block {
+	differing := map[string]bool{}
+	for _, diff := range diffs {
+		if diff.Transaction != "" {
+			differing[diff.Transaction] = true
+		}
+	}
+
+	trimmed := &pbsol.Block{
+		PreviousBlockhash: block.PreviousBlockhash,
+		Blockhash:         block.Blockhash,
+		ParentSlot:        block.ParentSlot,
+		Rewards:           block.Rewards,
+		BlockTime:         block.BlockTime,
+		BlockHeight:       block.BlockHeight,
+		Slot:              block.Slot,
+	}
+	for _, trx := range block.Transactions {
+		if differing[solana.Base58(transactionSignature(trx)).String()] {
+			trimmed.Transactions = append(trimmed.Transactions, trx)
+		}
+	}
+	return trimmed
+}
+
+// artifactFilename builds the artifact filename for a block, e.g. firehose_block_<slot>.pb
+func artifactFilename(prefix string, slot uint64, format ArtifactFormat) string {
+	return fmt.Sprintf("%s_%d.%s", prefix, slot, format)
+}
+
+// WithOutputLocation sets the directory artifacts are written to and a prefix prepended to
+// every artifact filename, e.g. "mainnet-" to keep environments apart
+func WithOutputLocation(dir, prefix string) Option {
+	return func(t *Tracker) {
+		t.outputDir = dir
+		t.outputPrefix = prefix
+	}
+}
+
+// artifactPath returns the full path of an artifact, honoring the output directory and prefix
+func (t *Tracker) artifactPath(name string, slot uint64) string {
+	return t.artifactLocation(t.outputPrefix + artifactFilename(name, slot, t.artifactFormat))
+}
+
+// WithDiffFile also writes the field diffs of every mismatch to diff_<slot>.json next to the
+// block artifacts, a small file listing exactly what differs
+func WithDiffFile() Option {
+	return func(t *Tracker) {
+		t.writeDiffFile = true
+	}
+}
+
+// writeFieldDiffs writes the field diffs of a mismatching slot as an indented JSON list and
+// returns the file path, the file is always JSON whatever the artifact format
+func (t *Tracker) writeFieldDiffs(slot uint64, diffs []FieldDiff) (string, error) {
+	filename := t.artifactLocation(t.outputPrefix + artifactFilename("diff", slot, ArtifactFormatJSON))
+
+	data, err := json.MarshalIndent(diffs, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal field diffs of slot %d: %w", slot, err)
+	}
+	if err := t.writeArtifactFile(filename, data); err != nil {
+		return "", fmt.Errorf("failed to write field diffs to file %s: %w", filename, err)
+	}
+	return filename, nil
+}
+
+// jsonMarshalOptions returns the protojson options used for JSON artifacts. Unpopulated fields
+// are emitted in the empty sanitize mode so emptied fields remain visible in the output, which
+// applies to the unset fields of the whole block, not only to the sanitized ones.
+func (t *Tracker) jsonMarshalOptions() protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		Indent:          "  ",
+		EmitUnpopulated: t.sanitizeMode == SanitizeModeEmpty,
+		UseProtoNames:   t.artifactProtoNames,
+	}
+}
+
+// writeBlockArtifacts writes both blocks to their respective files using the artifact format
+func (t *Tracker) writeBlockArtifacts(block1, block2 *pbsol.Block, filename1, filename2 string) error {
+	switch t.artifactFormat {
+	case ArtifactFormatPB:
+		return t.writeBlocksToPBFiles(block1, block2, filename1, filename2)
+	default:
+		return t.writeBlocksToJSONFiles(block1, block2, filename1, filename2, t.jsonMarshalOptions())
+	}
+}
+
+// writeBlocksToPBFiles writes both pbsol.Block objects to separate protobuf binary files
+func (t *Tracker) writeBlocksToPBFiles(block1, block2 *pbsol.Block, filename1, filename2 string) error {
+	// Marshal first block
+	data1, err := proto.Marshal(block1)
+	if err != nil {
+		return fmt.Errorf("failed to marshal first block to protobuf: %w", err)
+	}
+
+	// Marshal second block
+	data2, err := proto.Marshal(block2)
+	if err != nil {
+		return fmt.Errorf("failed to marshal second block to protobuf: %w", err)
+	}
+
+	// Write first block to file
+	err = t.writeArtifactFile(filename1, data1)
+	if err != nil {
+		return fmt.Errorf("failed to write first block to file %s: %w", filename1, err)
+	}
+
+	// Write second block to file
+	err = t.writeArtifactFile(filename2, data2)
+	if err != nil {
+		return fmt.Errorf("failed to write second block to file %s: %w", filename2, err)
+	}
+
+	return nil
+}
+
+// readBlockArtifact loads a block artifact, detecting the format from the file extension
+func readBlockArtifact(filename string) (*pbsol.Block, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact file %s: %w", filename, err)
+	}
+
+	var block pbsol.Block
+	switch ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."); ArtifactFormat(ext) {
+	case ArtifactFormatPB:
+		if err := proto.Unmarshal(data, &block); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal protobuf artifact %s: %w", filename, err)
+		}
+	case ArtifactFormatJSON:
+		if err := protojson.Unmarshal(data, &block); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON artifact %s: %w", filename, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown artifact extension %q for file %s (expected .json or .pb)", ext, filename)
+	}
+
+	return &block, nil
+}
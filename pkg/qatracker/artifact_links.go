@@ -0,0 +1,134 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+)
+
+// artifactSignTimeout bounds the signature of a single artifact URL
+const artifactSignTimeout = 10 * time.Second
+
+// ArtifactURLSigner returns a signed URL granting temporary read access to an object of the
+// output store, so alerts link artifacts of a private bucket
+type ArtifactURLSigner interface {
+	SignURL(ctx context.Context, objectName string) (string, error)
+}
+
+// WithArtifactPublicURL makes notifications link the artifacts of the output store as baseURL
+// followed by the object name, e.g. https://qa-artifacts.s3.amazonaws.com/mainnet for a public
+// bucket or a CDN in front of it
+func WithArtifactPublicURL(baseURL string) Option {
+	return func(t *Tracker) {
+		t.artifactPublicURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithArtifactURLSigner makes notifications link the artifacts of the output store with URLs
+// signed by signer
+func WithArtifactURLSigner(signer ArtifactURLSigner) Option {
+	return func(t *Tracker) {
+		t.artifactURLSigner = signer
+	}
+}
+
+// NewArtifactURLSigner creates the signer of the objects of an s3:// or gs:// output store, the
+// signed URLs expire after ttl. Credentials are picked up like for writing to the store.
+func NewArtifactURLSigner(ctx context.Context, storeURL string, ttl time.Duration) (ArtifactURLSigner, error) {
+	parsed, err := url.Parse(storeURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output store URL %s: %w", storeURL, err)
+	}
+	prefix := strings.Trim(parsed.Path, "/")
+
+	switch parsed.Scheme {
+	case "s3":
+		config := aws.NewConfig()
+		if region := parsed.Query().Get("region"); region != "" {
+			config = config.WithRegion(region)
+		}
+		sess, err := session.NewSession(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 session: %w", err)
+		}
+		return &s3URLSigner{client: s3.New(sess), bucket: parsed.Host, prefix: prefix, ttl: ttl}, nil
+
+	case "gs":
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+		return &gcsURLSigner{bucket: client.Bucket(parsed.Host), prefix: prefix, ttl: ttl}, nil
+
+	default:
+		return nil, fmt.Errorf("signed artifact URLs need an s3:// or gs:// output store, got %s", storeURL)
+	}
+}
+
+// s3URLSigner presigns S3 GetObject requests
+type s3URLSigner struct {
+	client *s3.S3
+	bucket string
+	prefix string
+	ttl    time.Duration
+}
+
+func (s *s3URLSigner) SignURL(ctx context.Context, objectName string) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path.Join(s.prefix, objectName)),
+	})
+	req.SetContext(ctx)
+	return req.Presign(s.ttl)
+}
+
+// gcsURLSigner signs V4 GCS URLs
+type gcsURLSigner struct {
+	bucket *storage.BucketHandle
+	prefix string
+	ttl    time.Duration
+}
+
+func (s *gcsURLSigner) SignURL(ctx context.Context, objectName string) (string, error) {
+	return s.bucket.SignedURL(path.Join(s.prefix, objectName), &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(s.ttl),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+// artifactLink returns the location of an artifact shown in notifications: its public or signed
+// URL when configured, its path or object URL otherwise. A failed signature falls back to the
+// object URL.
+func (t *Tracker) artifactLink(location string) string {
+	if t.outputStore == nil || location == "" || location == droppedArtifactLabel {
+		return location
+	}
+
+	name := path.Base(location)
+	switch {
+	case t.artifactPublicURL != "":
+		return t.artifactPublicURL + "/" + url.PathEscape(name)
+
+	case t.artifactURLSigner != nil:
+		ctx, cancel := context.WithTimeout(context.Background(), artifactSignTimeout)
+		defer cancel()
+
+		signed, err := t.artifactURLSigner.SignURL(ctx, name)
+		if err != nil {
+			t.logger.Warn("Failed to sign artifact URL, linking the object URL instead", zap.String("artifact", location), zap.Error(err))
+			return location
+		}
+		return signed
+	}
+	return location
+}
@@ -0,0 +1,100 @@
+package qatracker
+
+import (
+	"fmt"
+	"sync"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// droppedArtifactLabel replaces the artifact paths in notifications when the artifacts were dropped
+const droppedArtifactLabel = "(dropped, artifact queue full)"
+
+// artifactJob is a pair of mismatching blocks waiting to be written to artifact files
+type artifactJob struct {
+	slot              uint64
+	firehoseBlock     *pbsol.Block
+	rpcFetcherBlock   *pbsol.Block
+	firehoseFile      string
+	rpcFetcherFile    string
+	firehoseRaw       []byte
+	rpcFetcherRaw     []byte
+	firehoseRawName   string
+	rpcFetcherRawName string
+}
+
+// artifactQueue hands artifact jobs to a background writer so comparisons aren't blocked on disk
+type artifactQueue struct {
+	jobs    chan artifactJob
+	pending sync.WaitGroup
+}
+
+// WithArtifactQueue writes artifacts from a background writer through a queue of size jobs. When
+// the queue is full, the artifacts of a mismatch are dropped with a warning, its notification is
+// still sent. Each queued job holds a block pair in memory until written.
+func WithArtifactQueue(size int) Option {
+	return func(t *Tracker) {
+		t.artifactQueue = &artifactQueue{jobs: make(chan artifactJob, max(size, 1))}
+	}
+}
+
+// runArtifactWriter writes queued artifact jobs one at a time, failures are logged since the
+// comparison that produced them already completed
+func (t *Tracker) runArtifactWriter() {
+	for job := range t.artifactQueue.jobs {
+		if err := t.writeArtifacts(job); err != nil {
+			t.logger.Error("Failed to write queued block artifacts", zap.Uint64("slot", job.slot), zap.Error(err))
+		}
+		t.artifactQueue.pending.Done()
+	}
+}
+
+// enqueueArtifacts queues job for the background writer without blocking, it reports false
+// when the queue is full and the job was dropped
+func (t *Tracker) enqueueArtifacts(job artifactJob) bool {
+	t.artifactQueue.pending.Add(1)
+	select {
+	case t.artifactQueue.jobs <- job:
+		return true
+	default:
+		t.artifactQueue.pending.Done()
+		return false
+	}
+}
+
+// waitArtifacts blocks until every queued artifact job has been written
+func (t *Tracker) waitArtifacts() {
+	if t.artifactQueue == nil {
+		return
+	}
+	t.artifactQueue.pending.Wait()
+}
+
+// writeArtifacts writes the block pair of job, and the raw bytes it holds, to their files
+func (t *Tracker) writeArtifacts(job artifactJob) error {
+	err := t.writeBlockArtifacts(job.firehoseBlock, job.rpcFetcherBlock, job.firehoseFile, job.rpcFetcherFile)
+	if err != nil {
+		return fmt.Errorf("error writing blocks to artifact files: %w", err)
+	}
+
+	t.logger.Info("Block artifact files written",
+		zap.String("firehose_file", job.firehoseFile),
+		zap.String("rpc_fetcher_file", job.rpcFetcherFile))
+
+	if t.rawBlocks != nil {
+		firehoseRawFile, err := t.writeRawBytes(job.firehoseRaw, job.slot, job.firehoseRawName)
+		if err != nil {
+			return err
+		}
+		rpcFetcherRawFile, err := t.writeRawBytes(job.rpcFetcherRaw, job.slot, job.rpcFetcherRawName)
+		if err != nil {
+			return err
+		}
+		t.logger.Info("Raw block bytes files written",
+			zap.String("firehose_raw_file", firehoseRawFile),
+			zap.String("rpc_fetcher_raw_file", rpcFetcherRawFile))
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"go.uber.org/zap"
+)
+
+// WithBatch makes every tick compare the n newest finalized slots not compared yet instead of
+// only the head block
+func WithBatch(n int) Option {
+	return func(t *Tracker) {
+		t.batchSize = n
+	}
+}
+
+// compareBatch compares, in order, the newest finalized slots not compared yet (at most
+// batchSize of them) and advances the high-water mark of compared slots. Skipped slots have
+// no block on the Firehose stream and are passed over.
+func (t *Tracker) compareBatch(ctx context.Context) error {
+	head, err := t.rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get latest finalized slot: %w", ErrNetwork, err)
+	}
+
+	startSlot := uint64(0)
+	if head >= uint64(t.batchSize) {
+		startSlot = head - uint64(t.batchSize) + 1
+	}
+	if highWater := t.batchHighWater.Load(); highWater >= startSlot {
+		startSlot = highWater + 1
+	}
+	if startSlot > head {
+		t.logger.Info("No new finalized slots to compare", zap.Uint64("finalized_slot", head))
+		return nil
+	}
+
+	t.logger.Info("Comparing batch of finalized slots", zap.Uint64("start_slot", startSlot), zap.Uint64("stop_slot", head))
+	summary := &RangeSummary{Categories: map[string]int{}}
+	err = t.compareRangeStream(ctx, startSlot, head, summary, nil, func(slot uint64) {
+		t.batchHighWater.Store(slot)
+	})
+	if err != nil {
+		return err
+	}
+	// Slots at the end of the batch may have been skipped, they are covered all the same
+	t.batchHighWater.Store(head)
+
+	t.logger.Info("Batch comparison completed",
+		zap.Int("compared", summary.Compared),
+		zap.Int("mismatches", summary.Mismatches),
+		zap.Int("errors", summary.Errors),
+		zap.Uint64("high_water_slot", head))
+	return nil
+}
@@ -0,0 +1,169 @@
+package qatracker
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/klauspost/compress/zstd"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// bigtableBlocksTable is the table the Solana ledger uploader writes blocks to
+	bigtableBlocksTable = "blocks"
+	// bigtableBlockColumn holds a block as a compressed solana.storage.ConfirmedBlock message,
+	// blocks uploaded before protobuf was adopted are in the bincode "x:bin" column
+	bigtableBlockColumn = "x:proto"
+)
+
+// Compression methods prefixed to Bigtable cells, a little-endian uint32
+const (
+	bigtableNoCompression uint32 = iota
+	bigtableBzip2
+	bigtableGzip
+	bigtableZstd
+)
+
+// BigtableSource is a BlockSource reading the Solana Bigtable block archive, the backend of the
+// RPC nodes serving historical getBlock. It only holds rooted blocks, so it suits backfill
+// comparisons of older slots without loading public RPC endpoints.
+type BigtableSource struct {
+	instance string
+	client   *bigtable.Client
+	table    *bigtable.Table
+	logger   *zap.Logger
+}
+
+// NewBigtableSource opens the Bigtable instance of project (e.g. "solana-ledger"), with the
+// service account key in credentialsFile or the application default credentials when empty
+func NewBigtableSource(ctx context.Context, project, instance, credentialsFile string) (*BigtableSource, error) {
+	var opts []option.ClientOption
+	if credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+
+	client, err := bigtable.NewClient(ctx, project, instance, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Bigtable instance %s/%s: %w", project, instance, err)
+	}
+	return &BigtableSource{
+		instance: project + "/" + instance,
+		client:   client,
+		table:    client.Open(bigtableBlocksTable),
+	}, nil
+}
+
+// Name implements BlockSource
+func (s *BigtableSource) Name() string {
+	return "bigtable"
+}
+
+// Start implements BlockSource, the archive only holds rooted blocks whatever the commitment
+func (s *BigtableSource) Start(logger *zap.Logger, commitment rpc.CommitmentType) error {
+	s.logger = logger.With(zap.String("bigtable_instance", s.instance))
+	if commitment != rpc.CommitmentFinalized {
+		s.logger.Info("Bigtable only archives rooted blocks, recent head blocks won't be available yet", zap.String("commitment", string(commitment)))
+	}
+	return nil
+}
+
+// Block implements BlockSource. Rows are keyed by slot and only exist for produced blocks, so the
+// first row from the slot's key tells a skipped slot (a later row) from one not archived yet (no
+// row at all).
+func (s *BigtableSource) Block(ctx context.Context, slot uint64) (*pbsol.Block, error) {
+	key := bigtableSlotKey(slot)
+
+	var row bigtable.Row
+	err := s.table.ReadRows(ctx, bigtable.InfiniteRange(key), func(r bigtable.Row) bool {
+		row = r
+		return false
+	}, bigtable.LimitRows(1), bigtable.RowFilter(bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read Bigtable row %s: %w", ErrNetwork, key, err)
+	}
+
+	switch {
+	case row == nil:
+		return nil, fmt.Errorf("%w: slot %d is not in the Bigtable archive yet", ErrNotAvailable, slot)
+	case row.Key() != key:
+		return nil, fmt.Errorf("%w: the Bigtable archive has no block for slot %d, the next one is at row %s", ErrSkipped, slot, row.Key())
+	}
+
+	for _, cell := range row["x"] {
+		if cell.Column != bigtableBlockColumn {
+			continue
+		}
+
+		return bigtableBlock(cell.Value, slot)
+	}
+	return nil, fmt.Errorf("%w: Bigtable block %d has no %s cell, bincode blocks aren't supported", ErrDecode, slot, bigtableBlockColumn)
+}
+
+// Close implements BlockSource
+func (s *BigtableSource) Close() error {
+	return s.client.Close()
+}
+
+// bigtableBlock decodes the compressed solana.storage.ConfirmedBlock of a Bigtable cell. It
+// shares its field numbers with sf.solana.type.v1.Block, which only adds the slot. The fields
+// sf.solana.type.v1 doesn't define, e.g. num_partitions on epoch boundary blocks, are discarded
+// so they don't end up in the checksummed bytes.
+func bigtableBlock(cell []byte, slot uint64) (*pbsol.Block, error) {
+	raw, err := bigtableDecompress(cell)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decompress Bigtable block %d: %w", ErrDecode, slot, err)
+	}
+
+	var block pbsol.Block
+	if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(raw, &block); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal Bigtable block %d: %w", ErrDecode, slot, err)
+	}
+	block.Slot = slot
+	return &block, nil
+}
+
+// bigtableSlotKey returns the row key of slot, its zero-padded hexadecimal value
+func bigtableSlotKey(slot uint64) string {
+	return fmt.Sprintf("%016x", slot)
+}
+
+// bigtableDecompress decompresses a Bigtable cell according to its compression method prefix
+func bigtableDecompress(cell []byte) ([]byte, error) {
+	if len(cell) < 4 {
+		return nil, fmt.Errorf("cell of %d bytes is too short for its compression method", len(cell))
+	}
+	method, data := binary.LittleEndian.Uint32(cell), cell[4:]
+
+	switch method {
+	case bigtableNoCompression:
+		return data, nil
+	case bigtableBzip2:
+		return io.ReadAll(bzip2.NewReader(bytes.NewReader(data)))
+	case bigtableGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	case bigtableZstd:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("unknown compression method %d", method)
+	}
+}
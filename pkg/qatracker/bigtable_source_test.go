@@ -0,0 +1,96 @@
+package qatracker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBigtableBlock(t *testing.T) {
+	expected := &pbsol.Block{
+		PreviousBlockhash: "parent",
+		Blockhash:         "hash",
+		ParentSlot:        41,
+		Rewards:           []*pbsol.Reward{{Pubkey: "validator", Lamports: 1000, RewardType: pbsol.RewardType_Staking}},
+		BlockTime:         &pbsol.UnixTimestamp{Timestamp: 1700000000},
+		BlockHeight:       &pbsol.BlockHeight{BlockHeight: 40},
+	}
+
+	// num_partitions (field 8) of solana.storage.ConfirmedBlock has no sf.solana.type.v1.Block
+	// counterpart, a field a newer storage reward would carry is unknown as well
+	stored := proto.Clone(expected).(*pbsol.Block)
+	stored.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 8, protowire.VarintType), 4))
+	stored.Rewards[0].ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 100, protowire.VarintType), 1))
+	raw, err := proto.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	if _, err := writer.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected.Slot = 42
+	expectedChecksum, err := calculateRawChecksum(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method uint32
+		data   []byte
+	}{
+		{name: "uncompressed", method: bigtableNoCompression, data: raw},
+		{name: "gzip", method: bigtableGzip, data: gzipped.Bytes()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cell := binary.LittleEndian.AppendUint32(nil, test.method)
+			block, err := bigtableBlock(append(cell, test.data...), 42)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if unknown := block.ProtoReflect().GetUnknown(); len(unknown) > 0 {
+				t.Fatalf("expected the unknown block fields to be discarded, got %d bytes", len(unknown))
+			}
+			if !proto.Equal(block, expected) {
+				t.Fatalf("unexpected block\n got: %v\nwant: %v", block, expected)
+			}
+			checksum, err := calculateRawChecksum(block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if checksum != expectedChecksum {
+				t.Fatalf("expected the checksum of the block without unknown fields %s, got %s", expectedChecksum, checksum)
+			}
+		})
+	}
+}
+
+func TestBigtableBlockInvalidCell(t *testing.T) {
+	for name, cell := range map[string][]byte{
+		"too short":          {0, 0},
+		"unknown method":     {9, 0, 0, 0, 1},
+		"invalid protobuf":   {0, 0, 0, 0, 0xff},
+		"invalid gzip frame": {2, 0, 0, 0, 1, 2, 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := bigtableBlock(cell, 42); !errors.Is(err, ErrDecode) {
+				t.Fatalf("expected a decode error, got %v", err)
+			}
+		})
+	}
+}
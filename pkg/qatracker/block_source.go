@@ -0,0 +1,67 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// BlockSource is a live source of Solana blocks the Firehose blocks are compared against
+// instead of the RPC fetcher blocks, e.g. a Geyser plugin subscription
+type BlockSource interface {
+	// Name identifies the source in logs and prefixes its artifacts, e.g. "geyser"
+	Name() string
+	// Start connects the source once the tracker is configured, blocks are served at commitment
+	Start(logger *zap.Logger, commitment rpc.CommitmentType) error
+	// Block returns the block at slot, waiting until ctx is done for a slot the source didn't
+	// produce yet. A slot the source has no block for returns an ErrSkipped error.
+	Block(ctx context.Context, slot uint64) (*pbsol.Block, error)
+	Close() error
+}
+
+// WithBlockSource compares every Firehose block with the block of the same slot served by
+// source instead of the RPC fetcher block
+func WithBlockSource(source BlockSource) Option {
+	return func(t *Tracker) {
+		t.blockSource = source
+	}
+}
+
+// fetchSourceBlock fetches the block at slot from the block source, bounded by the RPC fetch
+// timeout since it takes the place of the RPC fetcher, and computes its sanitized checksum
+func (t *Tracker) fetchSourceBlock(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	fetchCtx, cancel := withFetchTimeout(ctx, t.rpcFetchTimeout)
+	defer cancel()
+
+	block, err := t.blockSource.Block(fetchCtx, slot)
+	if err != nil {
+		return nil, "", err
+	}
+	if t.filterProgram != nil {
+		filterBlockByProgram(block, t.filterProgram)
+	}
+
+	checksum, err := calculateScopedChecksum(block, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to calculate sanitized checksum: %w", err)
+	}
+	t.logger.Info("Block source sanitized checksum calculated", zap.String("source", t.blockSource.Name()), zap.String("checksum_sha256", checksum))
+
+	return block, checksum, nil
+}
+
+// compareWithBlockSource compares the Firehose block with the block of the same slot served by
+// the block source
+func (t *Tracker) compareWithBlockSource(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string) (*Result, error) {
+	name := t.blockSource.Name()
+	t.logger.Info("Fetching block from block source", zap.String("source", name), zap.Uint64("slot", firehoseBlock.Slot))
+	sourceBlock, sourceBlockSum, err := t.fetchSourceBlock(ctx, firehoseBlock.Slot)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block from %s: %w", name, err)
+	}
+
+	return t.compareFetchedBlocks(firehoseBlock, firehoseBlockSum, sourceBlock, sourceBlockSum, "firehose_block", name+"_block", nil)
+}
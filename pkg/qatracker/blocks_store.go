@@ -0,0 +1,173 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/streamingfast/bstream"
+	pbbstream "github.com/streamingfast/bstream/pb/sf/bstream/v1"
+	"github.com/streamingfast/dstore"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// mergedBlocksBundleSize is the number of slots covered by a merged blocks file
+const mergedBlocksBundleSize = 100
+
+// BlocksStoreAgainst selects the live source archived blocks are compared against
+type BlocksStoreAgainst string
+
+const (
+	// BlocksStoreAgainstFirehose compares archived blocks against the live Firehose blocks
+	BlocksStoreAgainstFirehose BlocksStoreAgainst = "firehose"
+	// BlocksStoreAgainstRPC compares archived blocks against the RPC fetcher blocks
+	BlocksStoreAgainstRPC BlocksStoreAgainst = "rpc"
+)
+
+// ParseBlocksStoreAgainst validates and returns the live source for the given value
+func ParseBlocksStoreAgainst(value string) (BlocksStoreAgainst, error) {
+	switch against := BlocksStoreAgainst(strings.ToLower(value)); against {
+	case BlocksStoreAgainstFirehose, BlocksStoreAgainstRPC:
+		return against, nil
+	default:
+		return "", fmt.Errorf("invalid blocks store source %q (valid values: firehose, rpc)", value)
+	}
+}
+
+// BlocksStore reads canonical blocks from a dstore merged blocks location, the authoritative
+// archive the live sources are validated against. The last read bundle is kept in memory since
+// consecutive slots share a bundle.
+type BlocksStore struct {
+	store   dstore.Store
+	against BlocksStoreAgainst
+
+	mu         sync.Mutex
+	bundleBase uint64
+	bundle     map[uint64]*pbbstream.Block
+}
+
+// NewBlocksStore opens the merged blocks store at url (e.g. gs://bucket/merged-blocks)
+func NewBlocksStore(url string, against BlocksStoreAgainst) (*BlocksStore, error) {
+	store, err := dstore.NewDBinStore(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocks store %s: %w", url, err)
+	}
+	return &BlocksStore{store: store, against: against}, nil
+}
+
+// WithBlocksStore compares the archived block of every Firehose block's slot against a live
+// source instead of comparing Firehose against the RPC fetcher
+func WithBlocksStore(store *BlocksStore) Option {
+	return func(t *Tracker) {
+		t.blocksStore = store
+	}
+}
+
+// readBlock returns the archived block of slot. A slot missing from its bundle was skipped, a
+// missing bundle is not merged yet.
+func (s *BlocksStore) readBlock(ctx context.Context, slot uint64) (*pbbstream.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := slot / mergedBlocksBundleSize * mergedBlocksBundleSize
+	if s.bundle == nil || s.bundleBase != base {
+		bundle, err := s.readBundle(ctx, base)
+		if err != nil {
+			return nil, err
+		}
+		s.bundleBase, s.bundle = base, bundle
+	}
+
+	block, found := s.bundle[slot]
+	if !found {
+		return nil, fmt.Errorf("%w: slot %d is not in the archived bundle %010d", ErrSkipped, slot, base)
+	}
+	return block, nil
+}
+
+// readBundle reads every block of the merged blocks file starting at base
+func (s *BlocksStore) readBundle(ctx context.Context, base uint64) (map[uint64]*pbbstream.Block, error) {
+	filename := fmt.Sprintf("%010d", base)
+	reader, err := s.store.OpenObject(ctx, filename)
+	if err != nil {
+		if errors.Is(err, dstore.ErrNotFound) {
+			return nil, fmt.Errorf("%w: merged blocks file %s not in the blocks store yet", ErrNotAvailable, filename)
+		}
+		return nil, fmt.Errorf("%w: failed to open merged blocks file %s: %w", ErrNetwork, filename, err)
+	}
+	defer reader.Close()
+
+	blockReader, err := bstream.NewDBinBlockReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read merged blocks file %s: %w", ErrDecode, filename, err)
+	}
+
+	bundle := map[uint64]*pbbstream.Block{}
+	for {
+		block, err := blockReader.Read()
+		if errors.Is(err, io.EOF) {
+			return bundle, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read block from merged blocks file %s: %w", ErrDecode, filename, err)
+		}
+		bundle[block.Number] = block
+	}
+}
+
+// fetchArchivedBlock decodes the archived block of slot and computes its sanitized checksum
+func (t *Tracker) fetchArchivedBlock(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	block, err := t.blocksStore.readBlock(ctx, slot)
+	if err != nil {
+		return nil, "", err
+	}
+	if block.Payload == nil || len(block.Payload.Value) == 0 {
+		return nil, "", fmt.Errorf("%w: archived block %d has an empty payload", ErrDecode, slot)
+	}
+
+	var solanaBlock pbsol.Block
+	if err := proto.Unmarshal(block.Payload.Value, &solanaBlock); err != nil {
+		return nil, "", fmt.Errorf("%w: failed to unmarshal archived Solana block: %w", ErrDecode, err)
+	}
+	t.keepRawBytes(&solanaBlock, block.Payload.Value)
+
+	if t.filterProgram != nil {
+		filterBlockByProgram(&solanaBlock, t.filterProgram)
+	}
+
+	checksum, err := calculateScopedChecksum(&solanaBlock, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to calculate sanitized checksum: %w", err)
+	}
+	t.logger.Info("Archived block sanitized checksum calculated", zap.String("checksum_sha256", checksum))
+
+	return &solanaBlock, checksum, nil
+}
+
+// compareWithBlocksStore compares the archived block of the Firehose block's slot against the
+// configured live source
+func (t *Tracker) compareWithBlocksStore(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string) (*Result, error) {
+	t.logger.Info("Fetching block from blocks store", zap.Uint64("slot", firehoseBlock.Slot))
+	archivedBlock, archivedBlockSum, err := t.fetchArchivedBlock(ctx, firehoseBlock.Slot)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block from blocks store: %w", err)
+	}
+	defer t.takeRawBytes(archivedBlock)
+
+	if t.blocksStore.against == BlocksStoreAgainstFirehose {
+		return t.compareFetchedBlocks(firehoseBlock, firehoseBlockSum, archivedBlock, archivedBlockSum, "firehose_block", "archived_block", nil)
+	}
+
+	rpcFetcherBlock, rpcFetcherBlockSum, err := t.fetchRPCFetcherBlock(ctx, firehoseBlock.Slot)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block with RPCFetcher: %w", err)
+	}
+	defer t.takeRawBytes(rpcFetcherBlock)
+
+	return t.compareFetchedBlocks(rpcFetcherBlock, rpcFetcherBlockSum, archivedBlock, archivedBlockSum, "rpc_fetcher_block", "archived_block", nil)
+}
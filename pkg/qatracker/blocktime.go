@@ -0,0 +1,71 @@
+package qatracker
+
+import (
+	"fmt"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithBlockTimeTolerance ignores BlockTime differences of at most seconds between both sources,
+// a known benign rounding difference at the source
+func WithBlockTimeTolerance(seconds int64) Option {
+	return func(t *Tracker) {
+		t.blockTimeTolerance = seconds
+	}
+}
+
+// blockTimeDelta returns the BlockTime difference in seconds between two blocks, ok is false
+// when either block has no BlockTime
+func blockTimeDelta(a, b *pbsol.Block) (delta int64, ok bool) {
+	if a.BlockTime == nil || b.BlockTime == nil {
+		return 0, false
+	}
+	return a.BlockTime.Timestamp - b.BlockTime.Timestamp, true
+}
+
+// blockTimeTolerated reports whether both blocks have a BlockTime that differs by a non-zero
+// amount within the tolerance
+func (t *Tracker) blockTimeTolerated(a, b *pbsol.Block) bool {
+	delta, ok := blockTimeDelta(a, b)
+	return ok && delta != 0 && max(delta, -delta) <= t.blockTimeTolerance
+}
+
+// applyBlockTimeTolerance returns the checksum of the second block computed as if it had the
+// same BlockTime as the Firehose block when their skew is within tolerance, its original
+// checksum otherwise. A skew beyond tolerance is logged with its exact delta.
+func (t *Tracker) applyBlockTimeTolerance(firehoseBlock, otherBlock *pbsol.Block, otherBlockSum string, normalized bool) (string, error) {
+	delta, ok := blockTimeDelta(firehoseBlock, otherBlock)
+	if !ok || delta == 0 {
+		return otherBlockSum, nil
+	}
+
+	if !t.blockTimeTolerated(firehoseBlock, otherBlock) {
+		t.logger.Warn("BlockTime differs beyond tolerance",
+			zap.Uint64("slot", firehoseBlock.Slot),
+			zap.Int64("delta_seconds", delta),
+			zap.Int64("tolerance_seconds", t.blockTimeTolerance))
+		return otherBlockSum, nil
+	}
+
+	t.logger.Debug("BlockTime differs within tolerance, ignoring",
+		zap.Uint64("slot", firehoseBlock.Slot),
+		zap.Int64("delta_seconds", delta))
+
+	original := otherBlock.BlockTime
+	defer func() { otherBlock.BlockTime = original }()
+	otherBlock.BlockTime = proto.Clone(firehoseBlock.BlockTime).(*pbsol.UnixTimestamp)
+
+	var checksum string
+	var err error
+	if normalized {
+		checksum, err = calculateNormalizedChecksum(otherBlock, t.checksumScope, t.ignoredFields)
+	} else {
+		checksum, err = calculateScopedChecksum(otherBlock, t.checksumScope, t.ignoredFields)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate BlockTime tolerant checksum: %w", err)
+	}
+	return checksum, nil
+}
@@ -0,0 +1,336 @@
+package qatracker
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// severityHigh marks field diffs that corrupt everything derived from them, such as account keys
+// which every instruction account index refers to
+const severityHigh = "high"
+
+// maxHighSeverityPaths is the maximum number of high severity paths listed in a notification
+const maxHighSeverityPaths = 5
+
+// maxDifferingPaths is the maximum number of differing field paths listed in a notification
+const maxDifferingPaths = 10
+
+// FieldDiff describes a single field that differs between the Firehose block and the block
+// from the second source
+type FieldDiff struct {
+	// Category groups related fields, e.g. Fee or InnerInstructions
+	Category string `json:"category"`
+	// Severity is severityHigh for foundational fields, empty otherwise
+	Severity string `json:"severity,omitempty"`
+	// Path locates the field in the block, transactions are identified by their signature
+	Path string `json:"path"`
+	// Transaction is the base58 signature of the transaction holding the field, if any
+	Transaction string `json:"transaction,omitempty"`
+	// Program is the base58 ID of the program invoked by a differing instruction, if any
+	Program    string `json:"program,omitempty"`
+	Firehose   string `json:"firehose"`
+	RPCFetcher string `json:"rpc_fetcher"`
+}
+
+// diffBlocks returns every field that differs between two blocks. Transactions are paired by
+// signature so that a reordering is reported once as TransactionOrder instead of cascading
+// into every subsequent field, transactions present on a single side are reported as
+// MissingTransactions.
+func diffBlocks(a, b *pbsol.Block) []FieldDiff {
+	d := &blockDiffer{}
+
+	d.add("Blockhash", "blockhash", a.Blockhash != b.Blockhash, a.Blockhash, b.Blockhash)
+	d.add("PreviousBlockhash", "previous_blockhash", a.PreviousBlockhash != b.PreviousBlockhash, a.PreviousBlockhash, b.PreviousBlockhash)
+	d.add("ParentSlot", "parent_slot", a.ParentSlot != b.ParentSlot, a.ParentSlot, b.ParentSlot)
+	d.add("BlockTime", "block_time", !proto.Equal(a.BlockTime, b.BlockTime), a.BlockTime, b.BlockTime)
+	d.add("BlockHeight", "block_height", !proto.Equal(a.BlockHeight, b.BlockHeight), a.BlockHeight, b.BlockHeight)
+	d.add("Rewards", "rewards", !messagesEqual(a.Rewards, b.Rewards), a.Rewards, b.Rewards)
+	d.add("TransactionCount", "transactions", len(a.Transactions) != len(b.Transactions), len(a.Transactions), len(b.Transactions))
+	d.add("TransactionOrder", "transactions", !sameTransactionOrder(a, b), "", "")
+
+	bySignature := make(map[string]*pbsol.ConfirmedTransaction, len(b.Transactions))
+	for _, trx := range b.Transactions {
+		bySignature[string(transactionSignature(trx))] = trx
+	}
+
+	paired := map[string]bool{}
+	for _, trx := range a.Transactions {
+		signature := transactionSignature(trx)
+		d.transaction = solana.Base58(signature).String()
+		path := "transactions[" + d.transaction + "]"
+
+		other, found := bySignature[string(signature)]
+		if !found {
+			d.add("MissingTransactions", path, true, "present", "missing")
+			continue
+		}
+
+		paired[string(signature)] = true
+		d.diffTransaction(path, trx, other)
+	}
+	for _, trx := range b.Transactions {
+		signature := transactionSignature(trx)
+		if !paired[string(signature)] {
+			d.transaction = solana.Base58(signature).String()
+			d.add("MissingTransactions", "transactions["+d.transaction+"]", true, "missing", "present")
+		}
+	}
+
+	// Only the fields known to differ in practice are walked above, fall back to a generic
+	// comparison so a difference in any other field is still located
+	if len(d.diffs) == 0 && !proto.Equal(a, b) {
+		return protoFieldDiffs(a, b)
+	}
+	return d.diffs
+}
+
+// diffCategories returns the sorted set of field categories that differ between two blocks
+func diffCategories(a, b *pbsol.Block) []string {
+	return FieldDiffCategories(diffBlocks(a, b))
+}
+
+// FieldDiffCategories returns the sorted set of categories of the given field diffs
+func FieldDiffCategories(diffs []FieldDiff) []string {
+	categories := map[string]bool{}
+	for _, diff := range diffs {
+		categories[diff.Category] = true
+	}
+
+	out := make([]string, 0, len(categories))
+	for category := range categories {
+		out = append(out, category)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TopCategories returns the categories of a tally sorted by decreasing count (ties broken
+// alphabetically), keeping at most limit entries when limit is positive
+func TopCategories(tally map[string]int, limit int) []string {
+	out := make([]string, 0, len(tally))
+	for category := range tally {
+		out = append(out, category)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if tally[out[i]] != tally[out[j]] {
+			return tally[out[i]] > tally[out[j]]
+		}
+		return out[i] < out[j]
+	})
+
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// blockDiffer accumulates the field diffs found while walking two blocks
+type blockDiffer struct {
+	diffs []FieldDiff
+	// transaction is the signature of the transaction being walked, empty for block fields
+	transaction string
+}
+
+// add records a diff for the field at path when differs is set, formatting both values
+func (d *blockDiffer) add(category, path string, differs bool, firehose, rpcFetcher any) {
+	if !differs {
+		return
+	}
+
+	d.diffs = append(d.diffs, FieldDiff{
+		Category:    category,
+		Path:        path,
+		Transaction: d.transaction,
+		Firehose:    formatDiffValue(firehose),
+		RPCFetcher:  formatDiffValue(rpcFetcher),
+	})
+}
+
+// diffTransaction records the fields that differ between two transactions sharing a signature
+func (d *blockDiffer) diffTransaction(path string, a, b *pbsol.ConfirmedTransaction) {
+	ta, tb := a.GetTransaction(), b.GetTransaction()
+	d.add("Signatures", path+".transaction.signatures", !bytesSlicesEqual(ta.GetSignatures(), tb.GetSignatures()), ta.GetSignatures(), tb.GetSignatures())
+
+	ma, mb := ta.GetMessage(), tb.GetMessage()
+	msg := path + ".transaction.message"
+	d.add("MessageHeader", msg+".header", !proto.Equal(ma.GetHeader(), mb.GetHeader()), ma.GetHeader(), mb.GetHeader())
+	d.diffAccountKeys(msg+".account_keys", ma.GetAccountKeys(), mb.GetAccountKeys())
+	d.add("RecentBlockhash", msg+".recent_blockhash", !bytes.Equal(ma.GetRecentBlockhash(), mb.GetRecentBlockhash()), ma.GetRecentBlockhash(), mb.GetRecentBlockhash())
+	keysA, keysB := transactionAccountKeys(a), transactionAccountKeys(b)
+	diffInstructions(d, "Instructions", msg+".instructions", keysA, keysB, ma.GetInstructions(), mb.GetInstructions())
+	d.add("Versioned", msg+".versioned", ma.GetVersioned() != mb.GetVersioned(), ma.GetVersioned(), mb.GetVersioned())
+	d.add("AddressTableLookups", msg+".address_table_lookups", !messagesEqual(ma.GetAddressTableLookups(), mb.GetAddressTableLookups()), ma.GetAddressTableLookups(), mb.GetAddressTableLookups())
+
+	ea, eb := a.GetMeta(), b.GetMeta()
+	meta := path + ".meta"
+	d.add("Err", meta+".err", !proto.Equal(ea.GetErr(), eb.GetErr()), ea.GetErr(), eb.GetErr())
+	d.add("Fee", meta+".fee", ea.GetFee() != eb.GetFee(), ea.GetFee(), eb.GetFee())
+	d.add("PreBalances", meta+".pre_balances", !slices.Equal(ea.GetPreBalances(), eb.GetPreBalances()), ea.GetPreBalances(), eb.GetPreBalances())
+	d.add("PostBalances", meta+".post_balances", !slices.Equal(ea.GetPostBalances(), eb.GetPostBalances()), ea.GetPostBalances(), eb.GetPostBalances())
+	d.diffInnerInstructions(meta+".inner_instructions", keysA, keysB, ea.GetInnerInstructions(), eb.GetInnerInstructions())
+	d.add("LogMessages", meta+".log_messages", !slices.Equal(ea.GetLogMessages(), eb.GetLogMessages()), ea.GetLogMessages(), eb.GetLogMessages())
+	d.add("PreTokenBalances", meta+".pre_token_balances", !messagesEqual(ea.GetPreTokenBalances(), eb.GetPreTokenBalances()), ea.GetPreTokenBalances(), eb.GetPreTokenBalances())
+	d.add("PostTokenBalances", meta+".post_token_balances", !messagesEqual(ea.GetPostTokenBalances(), eb.GetPostTokenBalances()), ea.GetPostTokenBalances(), eb.GetPostTokenBalances())
+	d.add("TransactionRewards", meta+".rewards", !messagesEqual(ea.GetRewards(), eb.GetRewards()), ea.GetRewards(), eb.GetRewards())
+	d.add("LoadedAddresses", meta+".loaded_writable_addresses", !bytesSlicesEqual(ea.GetLoadedWritableAddresses(), eb.GetLoadedWritableAddresses()),
+		ea.GetLoadedWritableAddresses(), eb.GetLoadedWritableAddresses())
+	d.add("LoadedAddresses", meta+".loaded_readonly_addresses", !bytesSlicesEqual(ea.GetLoadedReadonlyAddresses(), eb.GetLoadedReadonlyAddresses()),
+		ea.GetLoadedReadonlyAddresses(), eb.GetLoadedReadonlyAddresses())
+	d.add("ReturnData", meta+".return_data", !proto.Equal(ea.GetReturnData(), eb.GetReturnData()), ea.GetReturnData(), eb.GetReturnData())
+	d.add("ComputeUnitsConsumed", meta+".compute_units_consumed", ea.GetComputeUnitsConsumed() != eb.GetComputeUnitsConsumed() ||
+		hasComputeUnitsConsumed(ea) != hasComputeUnitsConsumed(eb), computeUnitsConsumedValue(ea), computeUnitsConsumedValue(eb))
+}
+
+// diffAccountKeys compares the static account keys of a message index by index. Instructions
+// reference accounts by index into this array, so any difference is reported as high severity.
+func (d *blockDiffer) diffAccountKeys(path string, a, b [][]byte) {
+	for i := range max(len(a), len(b)) {
+		var keyA, keyB any = "missing", "missing"
+		if i < len(a) {
+			keyA = a[i]
+		}
+		if i < len(b) {
+			keyB = b[i]
+		}
+		if i < len(a) && i < len(b) && bytes.Equal(a[i], b[i]) {
+			continue
+		}
+
+		d.add("AccountKeys", fmt.Sprintf("%s[%d]", path, i), true, keyA, keyB)
+		d.diffs[len(d.diffs)-1].Severity = severityHigh
+	}
+}
+
+// programInstruction is an instruction invoking the program at ProgramIdIndex in the account keys
+type programInstruction interface {
+	proto.Message
+	GetProgramIdIndex() uint32
+}
+
+// diffInstructions compares two instruction lists index by index, attributing every differing
+// instruction to the program it invokes. The Firehose side resolves the program unless the
+// instruction only exists on the other side.
+func diffInstructions[T programInstruction](d *blockDiffer, category, path string, keysA, keysB [][]byte, a, b []T) {
+	for i := range max(len(a), len(b)) {
+		var instructionA, instructionB any = "missing", "missing"
+		program := ""
+		if i < len(b) {
+			instructionB = b[i]
+			program = instructionProgram(keysB, b[i].GetProgramIdIndex())
+		}
+		if i < len(a) {
+			instructionA = a[i]
+			program = instructionProgram(keysA, a[i].GetProgramIdIndex())
+		}
+		if i < len(a) && i < len(b) && proto.Equal(a[i], b[i]) {
+			continue
+		}
+
+		d.add(category, fmt.Sprintf("%s[%d]", path, i), true, instructionA, instructionB)
+		d.diffs[len(d.diffs)-1].Program = program
+	}
+}
+
+// diffInnerInstructions pairs the inner instruction groups of two transactions by the index of
+// their outer instruction and compares each group instruction by instruction
+func (d *blockDiffer) diffInnerInstructions(path string, keysA, keysB [][]byte, a, b []*pbsol.InnerInstructions) {
+	groupsB := make(map[uint32]*pbsol.InnerInstructions, len(b))
+	for _, group := range b {
+		groupsB[group.Index] = group
+	}
+
+	seen := map[uint32]bool{}
+	for _, group := range a {
+		seen[group.Index] = true
+		diffInstructions(d, "InnerInstructions", fmt.Sprintf("%s[%d].instructions", path, group.Index), keysA, keysB, group.Instructions, groupsB[group.Index].GetInstructions())
+	}
+	for _, group := range b {
+		if !seen[group.Index] {
+			diffInstructions(d, "InnerInstructions", fmt.Sprintf("%s[%d].instructions", path, group.Index), keysA, keysB, nil, group.Instructions)
+		}
+	}
+}
+
+// transactionAccountKeys returns the account keys instruction indexes refer to: the static keys
+// followed by the writable and readonly addresses loaded from lookup tables
+func transactionAccountKeys(trx *pbsol.ConfirmedTransaction) [][]byte {
+	keys := slices.Clone(trx.GetTransaction().GetMessage().GetAccountKeys())
+	keys = append(keys, trx.GetMeta().GetLoadedWritableAddresses()...)
+	return append(keys, trx.GetMeta().GetLoadedReadonlyAddresses()...)
+}
+
+// instructionProgram returns the base58 program ID at index in keys, or "" when out of range
+func instructionProgram(keys [][]byte, index uint32) string {
+	if int(index) >= len(keys) {
+		return ""
+	}
+	return solana.Base58(keys[index]).String()
+}
+
+// ProgramTally counts the differing instructions per invoked program ID
+func ProgramTally(diffs []FieldDiff) map[string]int {
+	tally := map[string]int{}
+	for _, diff := range diffs {
+		if diff.Program != "" {
+			tally[diff.Program]++
+		}
+	}
+	return tally
+}
+
+// highSeverityPaths returns the paths of the high severity field diffs
+func highSeverityPaths(diffs []FieldDiff) []string {
+	var paths []string
+	for _, diff := range diffs {
+		if diff.Severity == severityHigh {
+			paths = append(paths, diff.Path)
+		}
+	}
+	return paths
+}
+
+// formatDiffValue renders a field value for a FieldDiff, bytes are base58 encoded like on chain
+func formatDiffValue(value any) string {
+	switch v := value.(type) {
+	case []byte:
+		return solana.Base58(v).String()
+	case [][]byte:
+		encoded := make([]string, len(v))
+		for i, b := range v {
+			encoded[i] = solana.Base58(b).String()
+		}
+		return "[" + strings.Join(encoded, " ") + "]"
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// messagesEqual compares two slices of proto messages element by element
+func messagesEqual[T proto.Message](a, b []T) bool {
+	return slices.EqualFunc(a, b, func(x, y T) bool { return proto.Equal(x, y) })
+}
+
+func bytesSlicesEqual(a, b [][]byte) bool {
+	return slices.EqualFunc(a, b, bytes.Equal)
+}
+
+func hasComputeUnitsConsumed(meta *pbsol.TransactionStatusMeta) bool {
+	return meta != nil && meta.ComputeUnitsConsumed != nil
+}
+
+// computeUnitsConsumedValue returns the consumed compute units, or "unset" when absent
+func computeUnitsConsumedValue(meta *pbsol.TransactionStatusMeta) any {
+	if !hasComputeUnitsConsumed(meta) {
+		return "unset"
+	}
+	return meta.GetComputeUnitsConsumed()
+}
@@ -0,0 +1,62 @@
+package qatracker
+
+import (
+	"fmt"
+	"strings"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// ChecksumScope selects the projection of the block the comparison checksum is computed over
+type ChecksumScope string
+
+const (
+	// ChecksumScopeFull covers the whole sanitized block
+	ChecksumScopeFull ChecksumScope = "full"
+	// ChecksumScopeHeader covers the slot, blockhash, parent, block time and transaction count,
+	// a cheap integrity check for continuous monitoring
+	ChecksumScopeHeader ChecksumScope = "header"
+	// ChecksumScopeTransactions covers the sanitized transaction list only
+	ChecksumScopeTransactions ChecksumScope = "transactions"
+)
+
+// ParseChecksumScope validates and returns the checksum scope for the given value
+func ParseChecksumScope(value string) (ChecksumScope, error) {
+	switch scope := ChecksumScope(strings.ToLower(value)); scope {
+	case ChecksumScopeFull, ChecksumScopeHeader, ChecksumScopeTransactions:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid checksum scope %q (valid values: full, header, transactions)", value)
+	}
+}
+
+// WithChecksumScope sets the projection of the block used to compute comparison checksums
+func WithChecksumScope(scope ChecksumScope) Option {
+	return func(t *Tracker) {
+		t.checksumScope = scope
+	}
+}
+
+// calculateScopedChecksum sanitizes the block (modifies the original) and calculates the
+// checksum of its projection for the given scope. Each scope yields a distinct checksum.
+func calculateScopedChecksum(block *pbsol.Block, scope ChecksumScope, fields IgnoredFields) (string, error) {
+	switch scope {
+	case ChecksumScopeHeader:
+		sanitizeBlock(block, fields)
+		header := fmt.Sprintf("slot=%d\nblockhash=%s\nprevious_blockhash=%s\nparent_slot=%d\nblock_time=%d\ntransaction_count=%d\n",
+			block.Slot, block.Blockhash, block.PreviousBlockhash, block.ParentSlot, block.GetBlockTime().GetTimestamp(), len(block.Transactions))
+		return calculateChecksum([]byte(header)), nil
+
+	case ChecksumScopeTransactions:
+		sanitizeBlock(block, fields)
+		data, err := proto.Marshal(&pbsol.Block{Transactions: block.Transactions})
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal sanitized transactions: %w", err)
+		}
+		return calculateChecksum(data), nil
+
+	default:
+		return calculateSanitizedChecksum(block, fields)
+	}
+}
@@ -0,0 +1,38 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CompareSlot compares the block at slot, or the Firehose head block when slot is 0
+func (t *Tracker) CompareSlot(ctx context.Context, slot uint64) (*Result, error) {
+	if slot == 0 {
+		firehoseBlock, firehoseBlockSum, err := t.fetchLatestBlock(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching block from Firehose: %w", err)
+		}
+		return t.compareHeadBlock(ctx, firehoseBlock, firehoseBlockSum)
+	}
+
+	// The slot is known up front, both sources are fetched concurrently
+	fetch, err := t.fetchSlotConcurrently(ctx, slot)
+	if errors.Is(err, ErrSkipped) {
+		if result := t.classifySkippedSlot(slot, "firehose"); result != nil {
+			return result, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The slot was requested, an RPC skip is classified like a Firehose one
+	result, err := t.compareSlotFetch(ctx, fetch)
+	if errors.Is(err, ErrSkipped) {
+		if result := t.classifySkippedSlot(slot, "rpc"); result != nil {
+			return result, nil
+		}
+	}
+	return result, err
+}
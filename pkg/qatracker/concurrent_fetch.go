@@ -0,0 +1,83 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"golang.org/x/sync/errgroup"
+)
+
+// slotFetch holds the blocks of a slot fetched from Firehose and through the RPC fetcher
+type slotFetch struct {
+	firehoseBlock      *pbsol.Block
+	firehoseBlockSum   string
+	rpcFetcherBlock    *pbsol.Block
+	rpcFetcherBlockSum string
+	// RPC fetch error telling the slot has no block, it's reported against the Firehose block
+	rpcFetcherErr error
+}
+
+// fetchSlotConcurrently fetches a slot known up front from Firehose and through the RPC fetcher
+// concurrently, instead of waiting for the Firehose block to learn the slot. A failure of either
+// fetch cancels the other, the returned error names the source that failed. An RPC answer that
+// the slot has no block doesn't cancel the Firehose fetch, it's kept in rpcFetcherErr. With a
+// blocks store, a peer Firehose or a block source, only the Firehose block is fetched, the other
+// block is read when comparing.
+func (t *Tracker) fetchSlotConcurrently(ctx context.Context, slot uint64) (*slotFetch, error) {
+	fetch := &slotFetch{}
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	group.Go(func() error {
+		block, checksum, err := t.fetchFirehoseBlock(groupCtx, slot)
+		if err != nil {
+			return fmt.Errorf("error fetching block from Firehose: %w", err)
+		}
+		fetch.firehoseBlock, fetch.firehoseBlockSum = block, checksum
+		return nil
+	})
+
+	if t.comparesRPCFetcher() {
+		group.Go(func() error {
+			block, checksum, err := t.fetchRPCFetcherBlock(groupCtx, slot)
+			if missingSlotCategory(err) != "" {
+				fetch.rpcFetcherErr = err
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("error fetching block with RPCFetcher: %w", err)
+			}
+			fetch.rpcFetcherBlock, fetch.rpcFetcherBlockSum = block, checksum
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		// The fetch that succeeded, if any, has its raw bytes released
+		if fetch.firehoseBlock != nil {
+			t.takeRawBytes(fetch.firehoseBlock)
+		}
+		if fetch.rpcFetcherBlock != nil {
+			t.takeRawBytes(fetch.rpcFetcherBlock)
+		}
+		return nil, err
+	}
+	return fetch, nil
+}
+
+// compareSlotFetch compares the blocks of a slot fetched concurrently. Like compareWithRPCFetcher,
+// an RPC skip of the slot returns an ErrSkipped error.
+func (t *Tracker) compareSlotFetch(ctx context.Context, fetch *slotFetch) (*Result, error) {
+	if !t.comparesRPCFetcher() {
+		return t.compareWithRPCFetcher(ctx, fetch.firehoseBlock, fetch.firehoseBlockSum)
+	}
+
+	defer t.takeRawBytes(fetch.firehoseBlock)
+	return t.compareRPCFetcherBlock(ctx, fetch.firehoseBlock, fetch.firehoseBlockSum, fetch.rpcFetcherBlock, fetch.rpcFetcherBlockSum, fetch.rpcFetcherErr)
+}
+
+// comparesRPCFetcher reports whether Firehose blocks are compared with the RPC fetcher blocks,
+// rather than with a blocks store, a peer Firehose or a block source
+func (t *Tracker) comparesRPCFetcher() bool {
+	return t.blocksStore == nil && t.peerFirehose == nil && t.blockSource == nil
+}
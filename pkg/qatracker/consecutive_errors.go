@@ -0,0 +1,32 @@
+package qatracker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithMaxConsecutiveErrors makes Run return an error once more than n comparisons in a
+// row failed, so a supervisor can restart the process clean (0 never gives up)
+func WithMaxConsecutiveErrors(n int) Option {
+	return func(t *Tracker) {
+		t.maxConsecutiveErrors = n
+	}
+}
+
+// recordComparisonOutcome tracks consecutive comparison failures, any success resets the count.
+// Skipped slots are not failures. It returns an error once the count exceeds the maximum.
+func (t *Tracker) recordComparisonOutcome(err error) error {
+	if err == nil {
+		t.consecutiveErrors.Store(0)
+		return nil
+	}
+	if errors.Is(err, ErrSkipped) {
+		return nil
+	}
+
+	count := t.consecutiveErrors.Add(1)
+	if t.maxConsecutiveErrors > 0 && count > int64(t.maxConsecutiveErrors) {
+		return fmt.Errorf("%d consecutive comparison errors exceed --max-consecutive-errors %d, last error: %w", count, t.maxConsecutiveErrors, err)
+	}
+	return nil
+}
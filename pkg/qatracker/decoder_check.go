@@ -0,0 +1,76 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// WithDecoderCheck switches the tracker to compare two decoders on the same Firehose bytes
+// instead of comparing Firehose against the RPC fetcher
+func WithDecoderCheck() Option {
+	return func(t *Tracker) {
+		t.decoderCheck = true
+	}
+}
+
+// compareDecoders fetches the latest Firehose block and decodes its raw bytes twice: once
+// through the regular generated pbsol decoder and once through a reflection-based decoder.
+// Since both sides see the exact same bytes, a mismatch points at decoding logic rather
+// than at source data or network differences.
+func (t *Tracker) compareDecoders(ctx context.Context) (*Result, error) {
+	t.logger.Info("Fetching latest block from StreamingFast Firehose for decoder check")
+	resp, err := t.fetchLatestResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block from Firehose: %w", err)
+	}
+
+	if resp.Block == nil {
+		return nil, fmt.Errorf("received empty block")
+	}
+	rawBlock := resp.Block.Value
+
+	firehoseBlock, firehoseBlockSum, err := t.decodeFirehoseResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding Firehose block: %w", err)
+	}
+	defer t.takeRawBytes(firehoseBlock)
+
+	reflectBlock, err := decodeBlockReflect(rawBlock)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding Firehose block with reflection decoder: %w", err)
+	}
+
+	if t.filterProgram != nil {
+		filterBlockByProgram(reflectBlock, t.filterProgram)
+	}
+
+	reflectBlockSum, err := calculateScopedChecksum(reflectBlock, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate sanitized checksum: %w", err)
+	}
+	t.logger.Info("Reflection decoded block sanitized checksum calculated",
+		zap.Uint64("slot", reflectBlock.Slot),
+		zap.String("checksum_sha256", reflectBlockSum))
+
+	return t.compareFetchedBlocks(firehoseBlock, firehoseBlockSum, reflectBlock, reflectBlockSum, "firehose_block", "reflect_decoded_block", nil)
+}
+
+// decodeBlockReflect decodes a serialized pbsol.Block using the generic dynamicpb decoder and
+// copies the result into a pbsol.Block field by field through protoreflect, so neither step
+// goes through the generated fast-path unmarshal code.
+func decodeBlockReflect(data []byte) (*pbsol.Block, error) {
+	block := &pbsol.Block{}
+
+	dynamicBlock := dynamicpb.NewMessage(block.ProtoReflect().Descriptor())
+	if err := proto.Unmarshal(data, dynamicBlock); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+
+	proto.Merge(block, dynamicBlock)
+	return block, nil
+}
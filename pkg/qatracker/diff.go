@@ -0,0 +1,56 @@
+package qatracker
+
+import (
+	"fmt"
+	"io"
+)
+
+// DiffArtifacts loads two block artifacts and re-runs the sanitized checksum comparison on them,
+// ignoring fields, the field diffs are only computed when the checksums differ
+func DiffArtifacts(firehoseFile, rpcFetcherFile string, fields IgnoredFields) (firehoseSum, rpcFetcherSum string, diffs []FieldDiff, err error) {
+	firehoseBlock, err := readBlockArtifact(firehoseFile)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	rpcFetcherBlock, err := readBlockArtifact(rpcFetcherFile)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	firehoseSum, err = calculateSanitizedChecksum(firehoseBlock, fields)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to calculate Firehose artifact checksum: %w", err)
+	}
+
+	rpcFetcherSum, err = calculateSanitizedChecksum(rpcFetcherBlock, fields)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to calculate RPC Fetcher artifact checksum: %w", err)
+	}
+
+	if firehoseSum != rpcFetcherSum {
+		diffs = diffBlocks(firehoseBlock, rpcFetcherBlock)
+		if isEpochBoundary(firehoseBlock) {
+			diffs = append(diffs, diffEpochBoundary(firehoseBlock, rpcFetcherBlock)...)
+		}
+	}
+
+	return firehoseSum, rpcFetcherSum, diffs, nil
+}
+
+// PrintFieldDiffs writes the field diffs in human-readable form, followed by the differing
+// instructions tallied by program
+func PrintFieldDiffs(out io.Writer, diffs []FieldDiff) {
+	for _, diff := range diffs {
+		if diff.Severity != "" {
+			fmt.Fprintf(out, "[%s severity] ", diff.Severity)
+		}
+		fmt.Fprintf(out, "%s (%s)\n  firehose:    %s\n  rpc fetcher: %s\n", diff.Path, diff.Category, diff.Firehose, diff.RPCFetcher)
+	}
+	if programs := ProgramTally(diffs); len(programs) > 0 {
+		fmt.Fprintln(out, "Differing instructions by program:")
+		for _, program := range TopCategories(programs, 0) {
+			fmt.Fprintf(out, "  %-44s %d\n", program, programs[program])
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxDigestSlots caps the number of mismatched slots listed in a digest email
+const maxDigestSlots = 20
+
+// maxDigestCategories caps the number of diff categories listed in a digest email
+const maxDigestCategories = 5
+
+// SMTPConfig holds the settings used to deliver digest emails
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// DigestSchedule tells when the email digest following a given time is due
+type DigestSchedule interface {
+	Next(time.Time) time.Time
+}
+
+// EmailNotifier aggregates comparison results and periodically sends them as an email digest
+type EmailNotifier struct {
+	smtp     SMTPConfig
+	schedule DigestSchedule
+
+	mu              sync.Mutex
+	since           time.Time
+	comparisons     int
+	mismatches      int
+	mismatchedSlots []uint64
+	categories      map[string]int
+}
+
+// WithEmailDigest enables the periodic email digest
+func WithEmailDigest(notifier *EmailNotifier) Option {
+	return func(t *Tracker) {
+		t.emailNotifier = notifier
+	}
+}
+
+// NewEmailNotifier creates an EmailNotifier sending a digest on schedule
+func NewEmailNotifier(config SMTPConfig, schedule DigestSchedule) (*EmailNotifier, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("smtp host is required for the email digest")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("smtp sender and at least one recipient are required for the email digest")
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("digest schedule is required for the email digest")
+	}
+
+	return &EmailNotifier{
+		smtp:       config,
+		schedule:   schedule,
+		since:      time.Now(),
+		categories: map[string]int{},
+	}, nil
+}
+
+// ParseDigestSchedule parses a digest schedule, either a Go duration (e.g. 12h) sending a digest
+// at that interval from the start of the process, or a standard 5-field cron expression
+// anchored to the wall clock (e.g. "0 9 * * 1-5") with the @hourly, @daily, @midnight, @weekly
+// and @monthly shorthands. Cron expressions use the local time zone unless prefixed with
+// CRON_TZ=<zone>.
+func ParseDigestSchedule(value string) (DigestSchedule, error) {
+	if interval, err := time.ParseDuration(value); err == nil {
+		if interval < time.Second {
+			return nil, fmt.Errorf("invalid digest schedule %q: the interval must be at least 1s", value)
+		}
+		return cron.Every(interval), nil
+	}
+
+	schedule, err := cron.ParseStandard(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest schedule %q: expected a duration (e.g. 24h) or a cron expression (e.g. \"0 9 * * *\" or @daily): %w", value, err)
+	}
+	// A valid expression may still name a date that never comes, e.g. February 30th
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid digest schedule %q: the cron expression never fires", value)
+	}
+	return schedule, nil
+}
+
+// Record adds a comparison result to the current digest period
+func (n *EmailNotifier) Record(result Result) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.comparisons++
+	if !result.Match {
+		n.mismatches++
+		if len(n.mismatchedSlots) < maxDigestSlots {
+			n.mismatchedSlots = append(n.mismatchedSlots, result.Slot)
+		}
+		for _, category := range result.DiffCategories {
+			n.categories[category]++
+		}
+	}
+}
+
+// Pending reports whether comparisons were recorded since the last digest
+func (n *EmailNotifier) Pending() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.comparisons > 0
+}
+
+// SendDigest emails the summary accumulated since the last digest and resets the counters
+func (n *EmailNotifier) SendDigest() error {
+	n.mu.Lock()
+	now := time.Now()
+	body := n.digestBody(now)
+	n.since = now
+	n.comparisons = 0
+	n.mismatches = 0
+	n.mismatchedSlots = nil
+	n.categories = map[string]int{}
+	n.mu.Unlock()
+
+	subject := fmt.Sprintf("Solana Block QA Digest - %s", now.Format("2006-01-02 15:04"))
+	if err := sendMail(n.smtp, subject, body); err != nil {
+		return fmt.Errorf("failed to send digest email: %w", err)
+	}
+
+	return nil
+}
+
+// sendMail sends a plain text email to the configured recipients
+func sendMail(config SMTPConfig, subject, body string) error {
+	message := "From: " + config.From + "\r\n" +
+		"To: " + strings.Join(config.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	return smtp.SendMail(addr, auth, config.From, config.To, []byte(message))
+}
+
+// digestBody renders the digest text, must be called with the lock held
+func (n *EmailNotifier) digestBody(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Solana Block QA Tracker digest\n")
+	fmt.Fprintf(&b, "Period: %s to %s\n\n", n.since.Format("2006-01-02 15:04:05"), now.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Comparisons: %d\n", n.comparisons)
+	fmt.Fprintf(&b, "Mismatches: %d\n", n.mismatches)
+
+	if categories := TopCategories(n.categories, maxDigestCategories); len(categories) > 0 {
+		fmt.Fprintf(&b, "\nTop diff categories:\n")
+		for _, category := range categories {
+			fmt.Fprintf(&b, "  - %s: %d\n", category, n.categories[category])
+		}
+	}
+
+	if len(n.mismatchedSlots) > 0 {
+		fmt.Fprintf(&b, "\nMismatched slots:\n")
+		for _, slot := range n.mismatchedSlots {
+			fmt.Fprintf(&b, "  - %d\n", slot)
+		}
+		if n.mismatches > len(n.mismatchedSlots) {
+			fmt.Fprintf(&b, "  ... and %d more\n", n.mismatches-len(n.mismatchedSlots))
+		}
+	}
+
+	return b.String()
+}
+
+// EmailAlertNotifier emails every mismatch event as it happens, unlike the EmailNotifier digest
+type EmailAlertNotifier struct {
+	smtp SMTPConfig
+}
+
+// NewEmailAlertNotifier creates an EmailAlertNotifier delivering through config
+func NewEmailAlertNotifier(config SMTPConfig) (*EmailAlertNotifier, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("smtp host is required for email alerts")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("smtp sender and at least one recipient are required for email alerts")
+	}
+	return &EmailAlertNotifier{smtp: config}, nil
+}
+
+// Notify emails the mismatch alert, listing the differing field paths
+func (n *EmailAlertNotifier) Notify(ctx context.Context, event MismatchEvent) error {
+	subject := fmt.Sprintf("Solana Block QA Alert - slot %d", event.Slot)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Block differences detected at slot %d\n", event.Slot)
+	if event.Environment != "" {
+		fmt.Fprintf(&b, "Environment: %s\n", event.Environment)
+	}
+	fmt.Fprintf(&b, "Network: %s\n", event.Network)
+	if event.EpochBoundary {
+		fmt.Fprintf(&b, "The slot is the first of an epoch\n")
+	}
+	fmt.Fprintf(&b, "Firehose checksum: %s\n", event.FirehoseChecksum)
+	fmt.Fprintf(&b, "RPC Fetcher checksum: %s\n", event.RPCFetcherChecksum)
+	fmt.Fprintf(&b, "Firehose artifact: %s\n", event.FirehoseFile)
+	fmt.Fprintf(&b, "RPC Fetcher artifact: %s\n", event.RPCFetcherFile)
+	if event.DiffFile != "" {
+		fmt.Fprintf(&b, "Diff file: %s\n", event.DiffFile)
+	}
+	fmt.Fprintf(&b, "Time: %s\n", event.Time.Format("2006-01-02 15:04:05"))
+	if len(event.DifferingTransactions) > 0 {
+		fmt.Fprintf(&b, "\nDiffering transactions (%d):\n", event.DifferingTransactionCount)
+		for _, trx := range event.DifferingTransactions {
+			fmt.Fprintf(&b, "  - #%d %s\n", trx.Index, trx.Signature)
+		}
+	}
+	if len(event.FieldDiffs) > 0 {
+		fmt.Fprintf(&b, "\nDiffering fields:\n")
+		for _, diff := range event.FieldDiffs {
+			fmt.Fprintf(&b, "  - %s (%s)\n", diff.Path, diff.Category)
+		}
+	}
+
+	// net/smtp has no context support, the send is only bounded by the server timeouts
+	if err := sendMail(n.smtp, subject, b.String()); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package qatracker
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDigestSchedule(t *testing.T) {
+	// A Friday afternoon, schedules without CRON_TZ are evaluated in the local time zone
+	now := time.Date(2026, time.October, 16, 14, 30, 15, 0, time.Local)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{value: "24h", want: now.Add(24 * time.Hour)},
+		{value: "90m", want: now.Add(90 * time.Minute)},
+		{value: "@hourly", want: time.Date(2026, time.October, 16, 15, 0, 0, 0, time.Local)},
+		{value: "@daily", want: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.Local)},
+		{value: "@midnight", want: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.Local)},
+		{value: "@weekly", want: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.Local)},
+		{value: "0 9 * * *", want: time.Date(2026, time.October, 17, 9, 0, 0, 0, time.Local)},
+		{value: "45 14 * * *", want: time.Date(2026, time.October, 16, 14, 45, 0, 0, time.Local)},
+		{value: "0 9 * * 1-5", want: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.Local)},
+		{value: "CRON_TZ=Europe/Paris 0 9 * * *", want: time.Date(2026, time.October, 17, 9, 0, 0, 0, paris)},
+		{value: "", wantErr: "invalid digest schedule"},
+		{value: "soon", wantErr: "invalid digest schedule"},
+		{value: "0 25 * * *", wantErr: "invalid digest schedule"},
+		{value: "0 9 * *", wantErr: "invalid digest schedule"},
+		{value: "0s", wantErr: "at least 1s"},
+		{value: "-1h", wantErr: "at least 1s"},
+		{value: "0 0 30 2 *", wantErr: "never fires"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			schedule, err := ParseDigestSchedule(test.value)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if next := schedule.Next(now); !next.Equal(test.want) {
+				t.Fatalf("expected the next digest at %s, got %s", test.want, next)
+			}
+		})
+	}
+}
+
+func TestDigestScheduleAnchoredToWallClock(t *testing.T) {
+	schedule, err := ParseDigestSchedule("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every digest of a daily schedule goes out at midnight, whenever the process started
+	next := time.Date(2026, time.October, 16, 3, 17, 0, 0, time.Local)
+	for range 3 {
+		next = schedule.Next(next)
+		if next.Hour() != 0 || next.Minute() != 0 || next.Second() != 0 {
+			t.Fatalf("expected a digest at midnight, got %s", next)
+		}
+	}
+	if want := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Fatalf("expected the third digest at %s, got %s", want, next)
+	}
+}
+
+func TestNewEmailNotifierRequiresSchedule(t *testing.T) {
+	config := SMTPConfig{Host: "smtp.example.com", From: "qa@example.com", To: []string{"team@example.com"}}
+	if _, err := NewEmailNotifier(config, nil); err == nil {
+		t.Fatal("expected an error without a schedule")
+	}
+}
@@ -0,0 +1,12 @@
+package qatracker
+
+import "strings"
+
+// envPrefix is prepended to the upper snake case flag name to build its environment variable,
+// e.g. --firehose-endpoint is bound to QA_FIREHOSE_ENDPOINT
+const envPrefix = "QA_"
+
+// EnvVarName returns the environment variable bound to a flag
+func EnvVarName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
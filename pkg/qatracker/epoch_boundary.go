@@ -0,0 +1,83 @@
+package qatracker
+
+import (
+	"fmt"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// slotsPerEpoch is the epoch length of every public cluster once warmup is over
+const slotsPerEpoch = 432_000
+
+// epochBoundaryCategory is the diff category of every difference found by the epoch boundary
+// comparison, it labels these rare but high-value mismatches distinctly
+const epochBoundaryCategory = "EpochBoundary"
+
+// epochOf returns the epoch holding slot
+func epochOf(slot uint64) uint64 {
+	return slot / slotsPerEpoch
+}
+
+// isEpochBoundary reports whether block is the first block of its epoch, i.e. its parent
+// belongs to a previous epoch (the first slots of an epoch may be skipped)
+func isEpochBoundary(block *pbsol.Block) bool {
+	return block.Slot > 0 && epochOf(block.ParentSlot) < epochOf(block.Slot)
+}
+
+// diffEpochBoundary is the stricter comparison applied to the first block of an epoch, whatever
+// the checksum scope. Rewards are paired by recipient and type so every differing reward is
+// reported individually, and the total of distributed lamports is checked since it changes the
+// supply.
+func diffEpochBoundary(a, b *pbsol.Block) []FieldDiff {
+	d := &blockDiffer{}
+
+	rewardKey := func(reward *pbsol.Reward) string {
+		return fmt.Sprintf("%s/%s", reward.Pubkey, reward.RewardType)
+	}
+	rewardsB := make(map[string]*pbsol.Reward, len(b.Rewards))
+	for _, reward := range b.Rewards {
+		rewardsB[rewardKey(reward)] = reward
+	}
+
+	paired := map[string]bool{}
+	for _, reward := range a.Rewards {
+		key := rewardKey(reward)
+		other, found := rewardsB[key]
+		if !found {
+			d.add(epochBoundaryCategory, "rewards["+key+"]", true, reward, "missing")
+			continue
+		}
+		paired[key] = true
+		d.add(epochBoundaryCategory, "rewards["+key+"]", !proto.Equal(reward, other), reward, other)
+	}
+	for _, reward := range b.Rewards {
+		if key := rewardKey(reward); !paired[key] {
+			d.add(epochBoundaryCategory, "rewards["+key+"]", true, "missing", reward)
+		}
+	}
+
+	totalA, totalB := rewardLamports(a.Rewards), rewardLamports(b.Rewards)
+	d.add(epochBoundaryCategory, "rewards.total_lamports", totalA != totalB, totalA, totalB)
+	d.add(epochBoundaryCategory, "rewards.count", len(a.Rewards) != len(b.Rewards), len(a.Rewards), len(b.Rewards))
+
+	return d.diffs
+}
+
+// rewardLamports returns the sum of the lamports distributed by rewards
+func rewardLamports(rewards []*pbsol.Reward) int64 {
+	var total int64
+	for _, reward := range rewards {
+		total += reward.Lamports
+	}
+	return total
+}
+
+// epochBoundaryLine returns the notification line labeling an epoch boundary mismatch, or an
+// empty string for any other slot
+func epochBoundaryLine(slot uint64, epochBoundary bool) string {
+	if !epochBoundary {
+		return ""
+	}
+	return fmt.Sprintf("• *Epoch boundary mismatch*: first block of epoch %d\n", epochOf(slot))
+}
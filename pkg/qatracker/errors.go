@@ -0,0 +1,123 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Typed errors returned by the fetch paths, check them with errors.Is to route, retry or alert
+// depending on the kind of failure
+var (
+	// ErrAuth means the source rejected the credentials
+	ErrAuth = errors.New("authentication failed")
+	// ErrDecode means the source returned data that could not be decoded into a block
+	ErrDecode = errors.New("decode failed")
+	// ErrNetwork means the source could not be reached or the connection failed, usually transient
+	ErrNetwork = errors.New("network failure")
+	// ErrSkipped means the slot was skipped by the leader, there is no block to compare
+	ErrSkipped = errors.New("slot skipped")
+	// ErrNotAvailable means the block exists but the source can't serve it (yet)
+	ErrNotAvailable = errors.New("block not available")
+	// ErrNotFound means the source answered that the slot has no block, with an error other than
+	// the skipped slot ones
+	ErrNotFound = errors.New("slot not found")
+	// ErrUnsupportedTransactionVersion means the block holds transactions of a version above the
+	// maximum the RPC node was asked to support
+	ErrUnsupportedTransactionVersion = errors.New("unsupported transaction version")
+	// ErrSlotMismatch means two sources returned blocks of different slots or parent slots for
+	// the same comparison, the blocks can't be compared
+	ErrSlotMismatch = errors.New("slot mismatch")
+)
+
+// rpcUnsupportedTransactionVersionCode is the JSON-RPC error code of a getBlock call whose block
+// holds transactions newer than maxSupportedTransactionVersion
+const rpcUnsupportedTransactionVersionCode = -32015
+
+// isTransactionVersionError reports whether err is the RPC node refusing a block because of
+// its transaction versions
+func isTransactionVersionError(err error) bool {
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == rpcUnsupportedTransactionVersionCode {
+		return true
+	}
+	return strings.Contains(err.Error(), "maxSupportedTransactionVersion")
+}
+
+// classifyFirehoseError wraps an error from the Firehose stream with its typed error, errors
+// that can't be classified are returned unchanged
+func classifyFirehoseError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, errStreamStalled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	return err
+}
+
+// classifyRPCFetcherError wraps an error from the RPC fetcher with its typed error, errors
+// that can't be classified are returned unchanged. The fetcher reports some conditions
+// through plain error messages, those are matched on their text.
+func classifyRPCFetcherError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	var rpcErr *jsonrpc.RPCError
+	switch message := err.Error(); {
+	case strings.Contains(message, "block not available"):
+		return fmt.Errorf("%w: %w", ErrNotAvailable, err)
+	case strings.Contains(message, "decoding block"):
+		return fmt.Errorf("%w: %w", ErrDecode, err)
+	case strings.Contains(message, "401") || strings.Contains(message, "403"):
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	case errors.As(err, &rpcErr):
+		// The RPC node answered, but with an error other than the skipped slot codes the
+		// fetcher already handles
+		return fmt.Errorf("%w: RPC error %d: %w", ErrNotFound, rpcErr.Code, err)
+	case errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+	return err
+}
+
+// errorKind returns a short label of the typed error wrapped by err, for logs and metrics
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return "auth"
+	case errors.Is(err, ErrDecode):
+		return "decode"
+	case errors.Is(err, ErrNetwork):
+		return "network"
+	case errors.Is(err, ErrSkipped):
+		return "skipped"
+	case errors.Is(err, ErrNotAvailable):
+		return "not_available"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnsupportedTransactionVersion):
+		return "unsupported_version"
+	case errors.Is(err, ErrSlotMismatch):
+		return slotMismatchCategory
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,74 @@
+package qatracker
+
+import (
+	"sync"
+	"time"
+)
+
+// maxFetchDurationSlots caps the number of slots whose fetch durations are kept until their
+// result is published, the lowest slots are dropped first
+const maxFetchDurationSlots = 1024
+
+// fetchDurations keeps the source fetch durations of slots until their result is published, for
+// the results log and the results sink
+type fetchDurations struct {
+	mu         sync.Mutex
+	firehose   map[uint64]time.Duration
+	rpcFetcher map[uint64]time.Duration
+}
+
+func newFetchDurations() *fetchDurations {
+	return &fetchDurations{firehose: map[uint64]time.Duration{}, rpcFetcher: map[uint64]time.Duration{}}
+}
+
+// record keeps the fetch duration of slot until its result is published. Fetches that never
+// lead to a result, e.g. when the other source failed, are dropped once too many slots are kept.
+func (d *fetchDurations) record(fetches map[uint64]time.Duration, slot uint64, duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fetches[slot] = duration
+	for len(fetches) > maxFetchDurationSlots {
+		lowest := slot
+		for kept := range fetches {
+			lowest = min(lowest, kept)
+		}
+		delete(fetches, lowest)
+	}
+}
+
+// take returns and forgets the fetch durations of slot, zero when not measured
+func (d *fetchDurations) take(slot uint64) (firehose, rpcFetcher time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	firehose, rpcFetcher = d.firehose[slot], d.rpcFetcher[slot]
+	delete(d.firehose, slot)
+	delete(d.rpcFetcher, slot)
+	return firehose, rpcFetcher
+}
+
+// recordFirehoseFetch keeps the duration of the Firehose fetch of slot started at start, when
+// fetch durations are stored
+func (t *Tracker) recordFirehoseFetch(slot uint64, start time.Time) {
+	if t.fetchDurations != nil {
+		t.fetchDurations.record(t.fetchDurations.firehose, slot, time.Since(start))
+	}
+}
+
+// recordRPCFetcherFetch keeps the duration of the RPC fetcher fetch of slot started at start,
+// when fetch durations are stored
+func (t *Tracker) recordRPCFetcherFetch(slot uint64, start time.Time) {
+	if t.fetchDurations != nil {
+		t.fetchDurations.record(t.fetchDurations.rpcFetcher, slot, time.Since(start))
+	}
+}
+
+// takeFetchDurations sets the fetch durations recorded for the slot of result
+func (t *Tracker) takeFetchDurations(result *Result) {
+	if t.fetchDurations == nil {
+		return
+	}
+	firehose, rpcFetcher := t.fetchDurations.take(result.Slot)
+	result.FirehoseFetchMs, result.RPCFetcherFetchMs = firehose.Milliseconds(), rpcFetcher.Milliseconds()
+}
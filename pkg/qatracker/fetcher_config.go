@@ -0,0 +1,166 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/streamingfast/firehose-solana/block/fetcher"
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// endpointInfoTimeout bounds the Firehose endpoint info request used to validate the fetcher config
+const endpointInfoTimeout = 10 * time.Second
+
+// FetcherConfig mirrors the options the production Firehose reader (firesol fetch rpc) was
+// deployed with. The RPC fetcher must be configured the same way, or every comparison reports
+// configuration-induced differences.
+type FetcherConfig struct {
+	// Network is the firesol --network value, it enables the mainnet specific block patches
+	Network string
+	// LatestBlockRetryInterval is the firesol --latest-block-retry-interval value
+	LatestBlockRetryInterval time.Duration
+	// Commitment is the commitment blocks are fetched at
+	Commitment rpc.CommitmentType
+	// MaxSupportedTransactionVersion is the highest transaction version the RPC returns
+	MaxSupportedTransactionVersion uint64
+	// Rewards controls whether block rewards are requested
+	Rewards bool
+}
+
+// DefaultFetcherConfig returns the configuration of the standard mainnet reader deployment
+func DefaultFetcherConfig() FetcherConfig {
+	return FetcherConfig{
+		Network:                        "mainnet",
+		LatestBlockRetryInterval:       5 * time.Second,
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: 0,
+		Rewards:                        true,
+	}
+}
+
+// ParseFetcherConfig parses a comma separated list of key=value pairs overriding the defaults,
+// e.g. "network=devnet,commitment=finalized,max-supported-transaction-version=0,rewards=true"
+func ParseFetcherConfig(value string) (FetcherConfig, error) {
+	config := DefaultFetcherConfig()
+	if strings.TrimSpace(value) == "" {
+		return config, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return config, fmt.Errorf("invalid fetcher config entry %q (expected key=value)", pair)
+		}
+
+		var err error
+		switch key = strings.ToLower(strings.TrimSpace(key)); key {
+		case "network":
+			config.Network, err = ParseNetwork(val)
+		case "latest-block-retry-interval":
+			config.LatestBlockRetryInterval, err = time.ParseDuration(val)
+		case "commitment":
+			config.Commitment, err = ParseCommitment(val)
+		case "max-supported-transaction-version":
+			config.MaxSupportedTransactionVersion, err = strconv.ParseUint(val, 10, 64)
+		case "rewards":
+			config.Rewards, err = strconv.ParseBool(val)
+		default:
+			err = fmt.Errorf("unknown key (valid keys: network, latest-block-retry-interval, commitment, max-supported-transaction-version, rewards)")
+		}
+		if err != nil {
+			return config, fmt.Errorf("invalid fetcher config %q: %w", key, err)
+		}
+	}
+
+	return config, nil
+}
+
+// ParseCommitment validates and returns the commitment for the given value, only the
+// commitments Firehose has a matching stream for are accepted
+func ParseCommitment(value string) (rpc.CommitmentType, error) {
+	switch commitment := rpc.CommitmentType(strings.ToLower(value)); commitment {
+	case rpc.CommitmentConfirmed, rpc.CommitmentFinalized:
+		return commitment, nil
+	default:
+		return "", fmt.Errorf("invalid commitment %q (valid values: confirmed, finalized)", value)
+	}
+}
+
+// HasFetcherConfigKey reports whether the fetcher config value explicitly sets key
+func HasFetcherConfigKey(value, key string) bool {
+	for _, pair := range strings.Split(value, ",") {
+		if k, _, ok := strings.Cut(strings.TrimSpace(pair), "="); ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// finalBlocksOnly reports whether Firehose must only stream final blocks to match the RPC
+// commitment, comparing a confirmed Firehose block with a finalized RPC one (or the other way
+// around) reports false mismatches on every fork
+func (c FetcherConfig) finalBlocksOnly() bool {
+	return c.Commitment == rpc.CommitmentFinalized
+}
+
+// isMainnet reports whether the mainnet specific fetcher patches apply
+func (c FetcherConfig) isMainnet() bool {
+	return c.Network == "mainnet" || c.Network == "mainnet-beta"
+}
+
+// newRPCFetcher creates the firehose-solana RPC fetcher for this config. The block request
+// options are package level in firehose-solana, so they are applied there.
+func (c FetcherConfig) newRPCFetcher(logger *zap.Logger) *fetcher.RPCFetcher {
+	fetcher.MaxSupportedTransactionVersion = c.MaxSupportedTransactionVersion
+	rewards := c.Rewards
+	fetcher.GetBlockOpts.Commitment = c.Commitment
+	fetcher.GetBlockOpts.MaxSupportedTransactionVersion = &fetcher.MaxSupportedTransactionVersion
+	fetcher.GetBlockOpts.Rewards = &rewards
+
+	return fetcher.NewRPC(c.LatestBlockRetryInterval, c.isMainnet(), false, logger)
+}
+
+// WithFetcherConfig configures the RPC fetcher to mirror the production reader deployment
+func WithFetcherConfig(config FetcherConfig) Option {
+	return func(t *Tracker) {
+		t.fetcherConfig = config
+	}
+}
+
+// validateFetcherConfig checks the configured network against the chain the Firehose endpoint
+// reports serving. Endpoints that don't implement the info service are not validated.
+func (t *Tracker) validateFetcherConfig(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, endpointInfoTimeout)
+	defer cancel()
+
+	conn, _ := t.firehoseConnection()
+	info, err := pbfirehose.NewEndpointInfoClient(conn).Info(ctx, &pbfirehose.InfoRequest{}, t.firehoseCallOptions()...)
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			t.logger.Debug("Firehose endpoint doesn't report its info, fetcher config not validated")
+			return nil
+		}
+		t.logger.Warn("Failed to get Firehose endpoint info, fetcher config not validated", zap.Error(err))
+		return nil
+	}
+
+	names := append([]string{info.ChainName}, info.ChainNameAliases...)
+	network := strings.TrimSuffix(t.fetcherConfig.Network, "-beta")
+	for _, name := range names {
+		for _, other := range []string{"mainnet", "devnet", "testnet"} {
+			if strings.Contains(name, other) && other != network {
+				return fmt.Errorf("fetcher config network %q doesn't match the Firehose endpoint chain %q", t.fetcherConfig.Network, info.ChainName)
+			}
+		}
+	}
+
+	t.logger.Info("Fetcher config validated against Firehose endpoint", zap.String("chain_name", info.ChainName), zap.String("network", t.fetcherConfig.Network))
+	return nil
+}
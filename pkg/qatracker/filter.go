@@ -0,0 +1,62 @@
+package qatracker
+
+import (
+	"bytes"
+
+	"github.com/gagliardetto/solana-go"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+)
+
+// WithProgramFilter restricts comparisons to transactions that invoke the given program
+func WithProgramFilter(programID solana.PublicKey) Option {
+	return func(t *Tracker) {
+		t.filterProgram = programID.Bytes()
+	}
+}
+
+// filterBlockByProgram removes every transaction that does not invoke programID, either
+// as a top-level instruction or as an inner instruction (modifies original)
+func filterBlockByProgram(block *pbsol.Block, programID []byte) {
+	filtered := block.Transactions[:0]
+	for _, trx := range block.Transactions {
+		if transactionInvokesProgram(trx, programID) {
+			filtered = append(filtered, trx)
+		}
+	}
+
+	// Clear the tail so dropped transactions can be garbage collected
+	for i := len(filtered); i < len(block.Transactions); i++ {
+		block.Transactions[i] = nil
+	}
+	block.Transactions = filtered
+}
+
+// transactionInvokesProgram reports whether any instruction of the transaction targets programID
+func transactionInvokesProgram(trx *pbsol.ConfirmedTransaction, programID []byte) bool {
+	if trx.Transaction == nil || trx.Transaction.Message == nil {
+		return false
+	}
+
+	accountKeys := transactionAccountKeys(trx)
+	isProgram := func(index uint32) bool {
+		return int(index) < len(accountKeys) && bytes.Equal(accountKeys[index], programID)
+	}
+
+	for _, instruction := range trx.Transaction.Message.Instructions {
+		if isProgram(instruction.ProgramIdIndex) {
+			return true
+		}
+	}
+
+	if trx.Meta != nil {
+		for _, inner := range trx.Meta.InnerInstructions {
+			for _, instruction := range inner.Instructions {
+				if isProgram(instruction.ProgramIdIndex) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
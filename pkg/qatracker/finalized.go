@@ -0,0 +1,77 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+)
+
+// WithCompareFinalized anchors every comparison to the RPC node's latest finalized slot instead
+// of the Firehose head, so the slot is guaranteed to exist and be final on the RPC side
+func WithCompareFinalized() Option {
+	return func(t *Tracker) {
+		t.compareFinalized = true
+	}
+}
+
+// compareLatestFinalized asks the RPC node for its latest finalized slot, then compares that
+// slot from both sources
+func (t *Tracker) compareLatestFinalized(ctx context.Context) error {
+	slot, err := t.rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get latest finalized slot: %w", ErrNetwork, err)
+	}
+
+	// The slot is known up front, both sources are fetched concurrently
+	t.logger.Info("Fetching latest finalized block from StreamingFast Firehose and the RPC fetcher", zap.Uint64("slot", slot))
+	fetch, err := t.fetchSlotConcurrently(ctx, slot)
+	if errors.Is(err, ErrSkipped) && t.classifySkippedSlot(slot, "firehose") != nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Firehose produced a block for the slot, an RPC skip is counted as a mismatch like for
+	// the head block
+	_, err = t.compareSlotFetch(ctx, fetch)
+	if errors.Is(err, ErrSkipped) {
+		t.reportRPCSkippedHeadBlock(fetch.firehoseBlock, fetch.firehoseBlockSum)
+		return nil
+	}
+	return err
+}
+
+// fetchFirehoseBlock fetches and unmarshals the final block at slot from Firehose. A skipped
+// slot has no block, the stream then ends without any response.
+func (t *Tracker) fetchFirehoseBlock(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	req := &pbfirehose.Request{
+		StartBlockNum:   int64(slot),
+		StopBlockNum:    slot,
+		FinalBlocksOnly: true,
+	}
+
+	start := time.Now()
+	resp, err := t.fetchFirehoseResponse(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	block, checksum, err := t.decodeFirehoseResponse(resp)
+	if err != nil {
+		return nil, "", err
+	}
+	if block.Slot != slot {
+		t.takeRawBytes(block)
+		return nil, "", fmt.Errorf("%w: Firehose returned slot %d but %d was requested, slot %d was likely skipped", ErrSkipped, block.Slot, slot, slot)
+	}
+	t.recordFirehoseFetch(slot, start)
+
+	return block, checksum, nil
+}
@@ -0,0 +1,209 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Follow keeps a single Firehose stream open from the head and compares every block as it
+// arrives, until ctx is cancelled. When the stream fails or ends, it is reopened from the cursor
+// of the last received block, with exponential backoff, so no block is missed or compared twice.
+// With the compared-slot state enabled, the cursor is persisted after every block and a restarted
+// tracker resumes from it instead of from the head. The servers and periodic tasks run like in
+// Run, the final digest and stats flush are left to FlushNotifications once Follow returned.
+func (t *Tracker) Follow(ctx context.Context) error {
+	if err := t.validateFetcherConfig(ctx); err != nil {
+		return err
+	}
+
+	var cursor string
+	if t.slotState != nil {
+		if err := t.loadSlotState(); err != nil {
+			return err
+		}
+		cursor = t.slotState.Cursor()
+	}
+
+	stopServers, err := t.startServers()
+	if err != nil {
+		return err
+	}
+	defer stopServers()
+	stopPeriodicTasks := t.startPeriodicTasks(ctx)
+	defer stopPeriodicTasks()
+
+	// Blocks compared one at a time share a single comparison slot covering the stream, the
+	// workers of a pool take one each
+	if t.concurrency <= 1 {
+		if err := t.acquireComparison(ctx); err != nil {
+			return err
+		}
+		defer t.releaseComparison()
+	}
+
+	if cursor != "" {
+		t.logger.Info("Resuming the Firehose stream from the persisted cursor",
+			zap.Uint64("last_compared_slot", t.slotState.LastComparedSlot()),
+			zap.Bool("final_blocks_only", t.fetcherConfig.finalBlocksOnly()))
+	} else {
+		t.logger.Info("Following the Firehose head", zap.Bool("final_blocks_only", t.fetcherConfig.finalBlocksOnly()))
+	}
+
+	backoff := firehoseReconnectInitialBackoff
+	for {
+		err := t.followStream(ctx, cursor, func(next string) {
+			cursor = next
+			backoff = firehoseReconnectInitialBackoff
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		// A cursor of another network or endpoint can never be resumed from
+		if cursor != "" && status.Code(err) == codes.InvalidArgument {
+			return fmt.Errorf("firehose rejected the cursor to resume from, remove it from the state file to restart at the head: %w", err)
+		}
+
+		var fatal *followFatalError
+		if errors.As(err, &fatal) {
+			return fatal.err
+		}
+
+		t.logger.Warn("Firehose stream interrupted, reopening it from the last cursor",
+			zap.Bool("has_cursor", cursor != ""),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, firehoseReconnectMaxBackoff)
+	}
+}
+
+// followFatalError stops Follow instead of reopening the stream
+type followFatalError struct {
+	err error
+}
+
+func (e *followFatalError) Error() string {
+	return e.err.Error()
+}
+
+// followStream opens a Firehose stream from cursor, or from the head when cursor is empty, and
+// compares every new block received, calling progress with the cursor of each response once it
+// and every previous response have been handled. It only returns once the stream fails or ends
+// and the running comparisons completed.
+func (t *Tracker) followStream(ctx context.Context, cursor string, progress func(cursor string)) (err error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Blocks compared in parallel complete out of order, a worker failing for good stops the stream
+	pool := t.newComparisonPool()
+	handled := newOrderedCursor(func(cursor string) {
+		t.followProgress(cursor, progress)
+	})
+	fatalC := make(chan error, 1)
+	defer func() {
+		pool.wait()
+		select {
+		case fatalErr := <-fatalC:
+			err = &followFatalError{err: fatalErr}
+		default:
+		}
+	}()
+
+	req := &pbfirehose.Request{
+		StartBlockNum:   -1,
+		Cursor:          cursor,
+		FinalBlocksOnly: t.fetcherConfig.finalBlocksOnly(),
+	}
+
+	conn, firehoseClient := t.firehoseConnection()
+	stream, err := firehoseClient.Blocks(streamCtx, req, t.firehoseCallOptions()...)
+	if err != nil {
+		return t.followStreamError(conn, "failed to create stream", err)
+	}
+
+	for seq := uint64(0); ; seq++ {
+		resp, err := t.recvWithWatchdog(stream, cancel)
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("firehose stream ended: %w", err)
+		}
+		if err != nil {
+			return t.followStreamError(conn, "failed to receive block", err)
+		}
+
+		// An undone block was already compared as new, the fork it belonged to is abandoned
+		if resp.Step == pbfirehose.ForkStep_STEP_UNDO {
+			handled.done(seq, resp.Cursor)
+			continue
+		}
+
+		firehoseBlock, firehoseBlockSum, err := t.decodeFirehoseResponse(resp)
+		if err != nil {
+			t.logger.Error("Error decoding Firehose block", zap.String("error_kind", errorKind(err)), zap.Error(err))
+			handled.done(seq, resp.Cursor)
+			if fatalErr := t.recordComparisonOutcome(err); fatalErr != nil {
+				return &followFatalError{err: fatalErr}
+			}
+			continue
+		}
+		t.observeHeadSlot(firehoseBlock)
+
+		err = pool.run(ctx, func() {
+			// A comparison aborted by the shutdown leaves the cursor before its block, so a
+			// resumed tracker compares it again
+			_, err := t.compareHeadBlock(ctx, firehoseBlock, firehoseBlockSum)
+			if err != nil && ctx.Err() != nil {
+				cancel()
+				return
+			}
+			handled.done(seq, resp.Cursor)
+			if err != nil {
+				t.logger.Error("Error comparing block", zap.Uint64("slot", firehoseBlock.Slot), zap.String("error_kind", errorKind(err)), zap.Error(err))
+				if t.statsCheckpoint != nil {
+					t.statsCheckpoint.RecordError()
+				}
+			}
+			if fatalErr := t.recordComparisonOutcome(err); fatalErr != nil {
+				select {
+				case fatalC <- fatalErr:
+				default:
+				}
+				cancel()
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// followProgress records the cursor of a handled response, in memory through progress and in the
+// compared-slot state when enabled
+func (t *Tracker) followProgress(cursor string, progress func(cursor string)) {
+	progress(cursor)
+	t.recordCursor(cursor)
+}
+
+// followStreamError classifies a stream failure, rebuilding the Firehose connection first when
+// the connection itself is broken
+func (t *Tracker) followStreamError(conn *grpc.ClientConn, message string, err error) error {
+	if isFirehoseConnectionError(err) {
+		if reconnectErr := t.reconnectFirehose(conn); reconnectErr != nil {
+			t.logger.Warn("Failed to reconnect to Firehose", zap.Error(reconnectErr))
+		}
+	}
+	return classifyFirehoseError(fmt.Errorf("%s: %w", message, err))
+}
@@ -0,0 +1,301 @@
+package qatracker
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	pbgeyser "solana-block-qa-tracker/pb/geyser"
+)
+
+const (
+	// geyserBlockRetention is the number of slots whose Geyser blocks are kept, about 3 minutes,
+	// a Firehose block older than that is not available
+	geyserBlockRetention = 512
+	// geyserSkipDistance is how far past a slot the subscription goes before a slot it sent no
+	// block for is considered skipped, blocks of nearby slots can arrive out of order
+	geyserSkipDistance = 32
+	// geyserMaxWait bounds the wait for the block of a slot, so a comparison without a fetch
+	// timeout doesn't hang on a broken subscription
+	geyserMaxWait = 30 * time.Second
+)
+
+// storageUnmarshal decodes solana.storage messages into their sf.solana.type.v1 counterparts,
+// the storage fields these don't define are discarded so they don't end up in the checksummed
+// bytes
+var storageUnmarshal = proto.UnmarshalOptions{DiscardUnknown: true}
+
+// GeyserSource is a BlockSource reconstructing blocks from a Yellowstone Geyser gRPC plugin
+// subscription. Blocks are pushed as the validator produces them, the recent ones are kept so
+// the Firehose block of a slot finds its Geyser block whichever arrived first.
+type GeyserSource struct {
+	endpoint string
+	xToken   string
+	conn     *grpc.ClientConn
+	client   pbgeyser.GeyserClient
+	logger   *zap.Logger
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	mu      sync.Mutex
+	blocks  map[uint64]*pbsol.Block
+	highest uint64
+	// Closed and replaced whenever a block arrives, waking up the waiting comparisons
+	arrived chan struct{}
+}
+
+// NewGeyserSource connects to the Yellowstone gRPC endpoint (e.g. "geyser.example.com:443"),
+// authenticating with xToken when set. The subscription starts with the tracker.
+func NewGeyserSource(endpoint, xToken string) (*GeyserSource, error) {
+	// Yellowstone endpoints are served over TLS like Firehose, with the same large messages
+	conn, err := dialFirehose(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Geyser %s: %w", endpoint, err)
+	}
+
+	return &GeyserSource{
+		endpoint: endpoint,
+		xToken:   xToken,
+		conn:     conn,
+		client:   pbgeyser.NewGeyserClient(conn),
+		blocks:   map[uint64]*pbsol.Block{},
+		arrived:  make(chan struct{}),
+	}, nil
+}
+
+// Name implements BlockSource
+func (s *GeyserSource) Name() string {
+	return "geyser"
+}
+
+// Start implements BlockSource, it subscribes to the blocks of commitment in the background and
+// resubscribes with exponential backoff when the subscription fails
+func (s *GeyserSource) Start(logger *zap.Logger, commitment rpc.CommitmentType) error {
+	level := pbgeyser.CommitmentLevel_CONFIRMED
+	if commitment == rpc.CommitmentFinalized {
+		level = pbgeyser.CommitmentLevel_FINALIZED
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.logger = logger.With(zap.String("geyser_endpoint", s.endpoint))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		backoff := firehoseReconnectInitialBackoff
+		for {
+			err := s.subscribe(ctx, level, func() { backoff = firehoseReconnectInitialBackoff })
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Warn("Geyser subscription failed, resubscribing", zap.Duration("backoff", backoff), zap.Error(err))
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, firehoseReconnectMaxBackoff)
+		}
+	}()
+	return nil
+}
+
+// subscribe runs one block subscription until it fails, received is called on every block
+func (s *GeyserSource) subscribe(ctx context.Context, level pbgeyser.CommitmentLevel, received func()) error {
+	if s.xToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-token", s.xToken)
+	}
+	stream, err := s.client.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	include, exclude := true, false
+	req := &pbgeyser.SubscribeRequest{
+		Blocks: map[string]*pbgeyser.SubscribeRequestFilterBlocks{
+			"qa": {IncludeTransactions: &include, IncludeAccounts: &exclude, IncludeEntries: &exclude},
+		},
+		Commitment: level,
+	}
+	if err := stream.Send(req); err != nil {
+		return err
+	}
+	s.logger.Info("Subscribed to Geyser blocks", zap.Stringer("commitment", level))
+
+	for {
+		update, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case update.GetPing() != nil:
+			// Load balancers in front of Yellowstone close streams the client never writes to
+			if err := stream.Send(&pbgeyser.SubscribeRequest{Ping: &pbgeyser.SubscribeRequestPing{Id: 1}}); err != nil {
+				return err
+			}
+		case update.GetBlock() != nil:
+			block, err := geyserBlock(update.GetBlock())
+			if err != nil {
+				s.logger.Warn("Failed to decode Geyser block", zap.Uint64("slot", update.GetBlock().Slot), zap.Error(err))
+				continue
+			}
+			s.add(block)
+			received()
+		}
+	}
+}
+
+// add keeps a received block and drops the blocks past the retention
+func (s *GeyserSource) add(block *pbsol.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[block.Slot] = block
+	if block.Slot > s.highest {
+		s.highest = block.Slot
+	}
+	for slot := range s.blocks {
+		if slot+geyserBlockRetention <= s.highest {
+			delete(s.blocks, slot)
+		}
+	}
+
+	close(s.arrived)
+	s.arrived = make(chan struct{})
+}
+
+// Block implements BlockSource. The returned block is a copy, the kept one is left untouched for
+// a later comparison of the same slot.
+func (s *GeyserSource) Block(ctx context.Context, slot uint64) (*pbsol.Block, error) {
+	ctx, cancel := context.WithTimeout(ctx, geyserMaxWait)
+	defer cancel()
+
+	for {
+		s.mu.Lock()
+		block, found := s.blocks[slot]
+		highest, arrived := s.highest, s.arrived
+		s.mu.Unlock()
+
+		switch {
+		case found:
+			return proto.Clone(block).(*pbsol.Block), nil
+		case highest >= slot+geyserBlockRetention:
+			return nil, fmt.Errorf("%w: slot %d is older than the Geyser blocks kept (highest slot %d)", ErrNotAvailable, slot, highest)
+		case highest >= slot+geyserSkipDistance:
+			return nil, fmt.Errorf("%w: Geyser sent no block for slot %d (highest slot %d)", ErrSkipped, slot, highest)
+		}
+
+		select {
+		case <-arrived:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: no Geyser block for slot %d yet (highest slot %d): %w", ErrNotAvailable, slot, highest, ctx.Err())
+		}
+	}
+}
+
+// Close implements BlockSource, it ends the subscription and closes the connection
+func (s *GeyserSource) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return s.conn.Close()
+}
+
+// geyserBlock reconstructs a Solana block from a Geyser block update. Its Solana storage
+// messages share the wire format of the sf.solana.type.v1 ones, they're decoded into those
+// without the fields only the storage messages define.
+func geyserBlock(update *pbgeyser.SubscribeUpdateBlock) (*pbsol.Block, error) {
+	block := &pbsol.Block{
+		PreviousBlockhash: update.ParentBlockhash,
+		Blockhash:         update.Blockhash,
+		ParentSlot:        update.ParentSlot,
+		Slot:              update.Slot,
+	}
+
+	rewards, err := geyserRewards(update.Rewards)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode rewards: %w", ErrDecode, err)
+	}
+	block.Rewards = rewards
+
+	if len(update.BlockTime) > 0 {
+		block.BlockTime = &pbsol.UnixTimestamp{}
+		if err := storageUnmarshal.Unmarshal(update.BlockTime, block.BlockTime); err != nil {
+			return nil, fmt.Errorf("%w: failed to decode block time: %w", ErrDecode, err)
+		}
+	}
+	if len(update.BlockHeight) > 0 {
+		block.BlockHeight = &pbsol.BlockHeight{}
+		if err := storageUnmarshal.Unmarshal(update.BlockHeight, block.BlockHeight); err != nil {
+			return nil, fmt.Errorf("%w: failed to decode block height: %w", ErrDecode, err)
+		}
+	}
+
+	// Transactions are in block order in Firehose blocks, Geyser gives each one its index
+	infos := slices.Clone(update.Transactions)
+	slices.SortFunc(infos, func(a, b *pbgeyser.SubscribeUpdateTransactionInfo) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	for _, info := range infos {
+		trx := &pbsol.ConfirmedTransaction{Transaction: &pbsol.Transaction{}, Meta: &pbsol.TransactionStatusMeta{}}
+		if err := storageUnmarshal.Unmarshal(info.Transaction, trx.Transaction); err != nil {
+			return nil, fmt.Errorf("%w: failed to decode transaction %d: %w", ErrDecode, info.Index, err)
+		}
+		if err := storageUnmarshal.Unmarshal(info.Meta, trx.Meta); err != nil {
+			return nil, fmt.Errorf("%w: failed to decode transaction %d meta: %w", ErrDecode, info.Index, err)
+		}
+		block.Transactions = append(block.Transactions, trx)
+	}
+	return block, nil
+}
+
+// geyserRewards decodes a solana.storage.ConfirmedBlock.Rewards message, its rewards (field 1)
+// are sf.solana.type.v1 rewards on the wire
+func geyserRewards(raw []byte) ([]*pbsol.Reward, error) {
+	var rewards []*pbsol.Reward
+	for len(raw) > 0 {
+		number, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		if number != 1 || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, typ, raw)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			raw = raw[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(raw)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		reward := &pbsol.Reward{}
+		if err := storageUnmarshal.Unmarshal(value, reward); err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+	return rewards, nil
+}
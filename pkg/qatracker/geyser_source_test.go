@@ -0,0 +1,64 @@
+package qatracker
+
+import (
+	"testing"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	pbgeyser "solana-block-qa-tracker/pb/geyser"
+)
+
+func TestGeyserBlock(t *testing.T) {
+	// A field only a newer solana.storage message defines is unknown to sf.solana.type.v1
+	unknownField := protowire.AppendVarint(protowire.AppendTag(nil, 100, protowire.VarintType), 1)
+	marshal := func(message proto.Message) []byte {
+		message = proto.Clone(message)
+		message.ProtoReflect().SetUnknown(unknownField)
+		data, err := proto.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := &pbsol.ConfirmedTransaction{Transaction: &pbsol.Transaction{Signatures: [][]byte{{1}}}, Meta: &pbsol.TransactionStatusMeta{Fee: 5000}}
+	second := &pbsol.ConfirmedTransaction{Transaction: &pbsol.Transaction{Signatures: [][]byte{{2}}}, Meta: &pbsol.TransactionStatusMeta{Fee: 10000}}
+	reward := &pbsol.Reward{Pubkey: "validator", Lamports: 1000}
+	rewards := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), marshal(reward))
+
+	update := &pbgeyser.SubscribeUpdateBlock{
+		Slot:            42,
+		Blockhash:       "hash",
+		ParentSlot:      41,
+		ParentBlockhash: "parent",
+		Rewards:         rewards,
+		BlockTime:       marshal(&pbsol.UnixTimestamp{Timestamp: 1700000000}),
+		BlockHeight:     marshal(&pbsol.BlockHeight{BlockHeight: 40}),
+		// Out of order, the block lists transactions by index
+		Transactions: []*pbgeyser.SubscribeUpdateTransactionInfo{
+			{Index: 1, Transaction: marshal(second.Transaction), Meta: marshal(second.Meta)},
+			{Index: 0, Transaction: marshal(first.Transaction), Meta: marshal(first.Meta)},
+		},
+	}
+
+	block, err := geyserBlock(update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &pbsol.Block{
+		PreviousBlockhash: "parent",
+		Blockhash:         "hash",
+		ParentSlot:        41,
+		Slot:              42,
+		Transactions:      []*pbsol.ConfirmedTransaction{first, second},
+		Rewards:           []*pbsol.Reward{reward},
+		BlockTime:         &pbsol.UnixTimestamp{Timestamp: 1700000000},
+		BlockHeight:       &pbsol.BlockHeight{BlockHeight: 40},
+	}
+	if !proto.Equal(block, expected) {
+		t.Fatalf("unexpected block\n got: %v\nwant: %v", block, expected)
+	}
+}
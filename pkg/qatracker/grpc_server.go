@@ -0,0 +1,195 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pbqa "solana-block-qa-tracker/pb/sf/solana/qa/v1"
+)
+
+// grpcShutdownTimeout bounds how long the gRPC server waits for in-flight calls on shutdown
+const grpcShutdownTimeout = 5 * time.Second
+
+// grpcWatchBuffer is the number of results buffered for a Watch stream, a slower subscriber
+// misses results rather than blocking comparisons
+const grpcWatchBuffer = 256
+
+// GRPCServer serves the sf.solana.qa.v1.Tracker gRPC service: on-demand comparisons with
+// Compare, and the results of every comparison the tracker runs with Watch
+type GRPCServer struct {
+	pbqa.UnimplementedTrackerServer
+
+	listenAddr string
+	tracker    *Tracker
+	server     *grpc.Server
+
+	mu       sync.Mutex
+	watchers map[*grpcWatcher]struct{}
+	// Closed on shutdown to end the Watch streams
+	done chan struct{}
+}
+
+// grpcWatcher is a subscriber of a Watch stream
+type grpcWatcher struct {
+	results        chan *pbqa.ComparisonResult
+	mismatchesOnly bool
+}
+
+// NewGRPCServer creates the gRPC service served on listenAddr, e.g. ":9000", once started
+func NewGRPCServer(listenAddr string) *GRPCServer {
+	s := &GRPCServer{
+		listenAddr: listenAddr,
+		server:     grpc.NewServer(),
+		watchers:   map[*grpcWatcher]struct{}{},
+		done:       make(chan struct{}),
+	}
+	pbqa.RegisterTrackerServer(s.server, s)
+	return s
+}
+
+// WithGRPCServer serves the gRPC service while the tracker runs
+func WithGRPCServer(server *GRPCServer) Option {
+	return func(t *Tracker) {
+		server.tracker = t
+		t.grpcServer = server
+	}
+}
+
+// Start listens on the gRPC address and serves the service in the background, a listen error
+// is returned immediately
+func (s *GRPCServer) Start(logger *zap.Logger) error {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", s.listenAddr, err)
+	}
+
+	logger.Info("Serving QA gRPC service", zap.String("listen_addr", listener.Addr().String()))
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Shutdown ends the Watch streams and stops the server, letting in-flight comparisons complete
+// within the shutdown timeout
+func (s *GRPCServer) Shutdown() {
+	close(s.done)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(grpcShutdownTimeout):
+		s.server.Stop()
+	}
+}
+
+// Compare runs an on-demand comparison. A comparison slot is taken without waiting, so callers
+// are told to retry instead of piling up calls holding blocks in memory.
+func (s *GRPCServer) Compare(ctx context.Context, req *pbqa.CompareRequest) (*pbqa.ComparisonResult, error) {
+	if !s.tracker.tryAcquireComparison() {
+		return nil, status.Error(codes.ResourceExhausted, "all comparison slots are in use, retry later")
+	}
+	defer s.tracker.releaseComparison()
+
+	result, err := s.tracker.CompareSlot(ctx, req.Slot)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		s.tracker.logger.Warn("On-demand comparison failed", zap.Uint64("slot", req.Slot), zap.Error(err))
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return comparisonResultProto(*result), nil
+}
+
+// Watch streams the results published from now on until the caller goes away or the server
+// shuts down
+func (s *GRPCServer) Watch(req *pbqa.WatchRequest, stream grpc.ServerStreamingServer[pbqa.ComparisonResult]) error {
+	watcher := &grpcWatcher{results: make(chan *pbqa.ComparisonResult, grpcWatchBuffer), mismatchesOnly: req.MismatchesOnly}
+
+	s.mu.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, watcher)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case result := <-watcher.results:
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "tracker shutting down")
+		}
+	}
+}
+
+// Record sends a comparison result to the Watch streams, it never blocks
+func (s *GRPCServer) Record(result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.watchers) == 0 {
+		return
+	}
+	message := comparisonResultProto(result)
+	for watcher := range s.watchers {
+		if watcher.mismatchesOnly && result.Match {
+			continue
+		}
+		select {
+		case watcher.results <- message:
+		default:
+			s.tracker.logger.Warn("gRPC watcher too slow, dropping comparison result", zap.Uint64("slot", result.Slot))
+		}
+	}
+}
+
+// startGRPCServer starts the gRPC server when enabled, the returned function stops it
+func (t *Tracker) startGRPCServer() (func(), error) {
+	if t.grpcServer == nil {
+		return func() {}, nil
+	}
+	if err := t.grpcServer.Start(t.logger); err != nil {
+		return nil, err
+	}
+	return t.grpcServer.Shutdown, nil
+}
+
+// comparisonResultProto converts a comparison result to its gRPC message
+func comparisonResultProto(result Result) *pbqa.ComparisonResult {
+	return &pbqa.ComparisonResult{
+		Slot:               result.Slot,
+		Match:              result.Match,
+		Skipped:            result.Skipped,
+		FirehoseChecksum:   result.FirehoseChecksum,
+		RpcFetcherChecksum: result.RPCFetcherChecksum,
+		DiffCategories:     result.DiffCategories,
+		FirehoseFile:       result.FirehoseFile,
+		RpcFetcherFile:     result.RPCFetcherFile,
+		Time:               timestamppb.New(result.Time),
+	}
+}
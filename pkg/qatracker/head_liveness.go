@@ -0,0 +1,117 @@
+package qatracker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// slotDuration is the target Solana slot time, used to estimate how far behind real time a slot is
+const slotDuration = 400 * time.Millisecond
+
+// headLiveness tracks the most recently observed Firehose head slot and when it last advanced
+type headLiveness struct {
+	stallTimeout time.Duration
+
+	mu          sync.Mutex
+	slot        uint64
+	slotsBehind int64
+	advancedAt  time.Time
+	stalled     bool
+}
+
+// WithHeadStallTimeout alerts when the Firehose head slot doesn't advance for timeout, which
+// catches a stalled source that would otherwise keep matching the same stale slot
+func WithHeadStallTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.headLiveness = &headLiveness{stallTimeout: timeout}
+	}
+}
+
+// slotsBehindRealTime estimates how many slots were produced since block, based on its block time
+func slotsBehindRealTime(block *pbsol.Block, now time.Time) int64 {
+	if block.BlockTime == nil {
+		return 0
+	}
+	return int64(now.Sub(time.Unix(block.BlockTime.Timestamp, 0)) / slotDuration)
+}
+
+// observeHeadSlot records the head slot of a Firehose block and notifies when it advances again
+// after a stall
+func (t *Tracker) observeHeadSlot(block *pbsol.Block) {
+	if t.headLiveness == nil {
+		return
+	}
+
+	now := time.Now()
+	behind := slotsBehindRealTime(block, now)
+	if t.statsCheckpoint != nil {
+		t.statsCheckpoint.RecordHead(block.Slot, behind)
+	}
+
+	h := t.headLiveness
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.slotsBehind = behind
+	if block.Slot <= h.slot {
+		return
+	}
+	h.slot = block.Slot
+	h.advancedAt = now
+
+	t.logger.Debug("Firehose head slot advanced", zap.Uint64("head_slot", block.Slot), zap.Int64("slots_behind", behind))
+
+	if h.stalled {
+		h.stalled = false
+		t.logger.Info("Firehose head slot advancing again", zap.Uint64("head_slot", block.Slot))
+
+		message := fmt.Sprintf("✅ *Solana Block QA Head Recovered* ✅\n"+
+			"%s"+
+			"Firehose head slot advancing again at slot %d (~%d slots behind real time)\n"+
+			"• Time: %s",
+			t.environmentLine(), block.Slot, behind, now.Format("2006-01-02 15:04:05"))
+		if err := t.postSlackMessage(message); err != nil {
+			t.logger.Error("Failed to send head recovery Slack notification", zap.Error(err))
+		}
+	}
+}
+
+// checkHeadStall alerts once when the head slot hasn't advanced for the stall timeout, the
+// alert re-arms once the head advances
+func (t *Tracker) checkHeadStall() {
+	if t.headLiveness == nil {
+		return
+	}
+
+	h := t.headLiveness
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.advancedAt.IsZero() || h.stalled {
+		return
+	}
+	stalledFor := time.Since(h.advancedAt)
+	if stalledFor < h.stallTimeout {
+		return
+	}
+	h.stalled = true
+
+	t.logger.Warn("Firehose head slot stopped advancing",
+		zap.Uint64("head_slot", h.slot),
+		zap.Int64("slots_behind", h.slotsBehind),
+		zap.Duration("stalled_for", stalledFor))
+
+	message := fmt.Sprintf("⏸️ *Solana Block QA Head Stalled* ⏸️\n"+
+		"%s"+
+		"Firehose head slot stuck at %d for %s (~%d slots behind real time)\n"+
+		"Comparisons may keep matching the same stale slot\n"+
+		"• Time: %s",
+		t.environmentLine(), h.slot, stalledFor.Round(time.Second), h.slotsBehind, time.Now().Format("2006-01-02 15:04:05"))
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send head stall Slack notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,80 @@
+package qatracker
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// healthState is the divergence state tracked in notify-on-recovery-only mode
+type healthState int
+
+const (
+	healthHealthy healthState = iota
+	healthDiverged
+)
+
+func (s healthState) String() string {
+	if s == healthDiverged {
+		return "diverged"
+	}
+	return "healthy"
+}
+
+// WithNotifyOnRecoveryOnly replaces the per-mismatch alerts with one alert when mismatches begin
+// (entering "diverged") and one when they stop (returning to "healthy")
+func WithNotifyOnRecoveryOnly() Option {
+	return func(t *Tracker) {
+		t.notifyOnRecoveryOnly = true
+	}
+}
+
+// recordHealth advances the health state machine with a comparison result and notifies on
+// transitions, intermediate mismatches are only counted
+func (t *Tracker) recordHealth(result Result) {
+	if !t.notifyOnRecoveryOnly {
+		return
+	}
+
+	switch {
+	case t.health == healthHealthy && !result.Match:
+		t.health = healthDiverged
+		t.divergedSince = result.Time
+		t.divergedMismatches = 1
+
+		t.logger.Warn("Sources diverged", zap.Uint64("slot", result.Slot))
+		message := fmt.Sprintf("🚨 *Solana Block QA Diverged* 🚨\n"+
+			"%s"+
+			"Block differences started at slot %d, further mismatches won't be notified until recovery\n"+
+			"• Firehose artifact: %s\n"+
+			"• RPC Fetcher artifact: %s\n"+
+			"• Time: %s",
+			t.environmentLine(), result.Slot, slackArtifact(t.artifactLink(result.FirehoseFile)), slackArtifact(t.artifactLink(result.RPCFetcherFile)), result.Time.Format("2006-01-02 15:04:05"))
+		if err := t.postSlackMessage(message); err != nil {
+			t.logger.Error("Failed to send divergence Slack notification", zap.Error(err))
+		}
+
+	case t.health == healthDiverged && !result.Match:
+		t.divergedMismatches++
+
+	case t.health == healthDiverged && result.Match:
+		t.health = healthHealthy
+		duration := result.Time.Sub(t.divergedSince).Round(time.Second)
+
+		t.logger.Info("Sources recovered",
+			zap.Uint64("slot", result.Slot),
+			zap.Duration("diverged_for", duration),
+			zap.Int("mismatches", t.divergedMismatches))
+		message := fmt.Sprintf("✅ *Solana Block QA Recovered* ✅\n"+
+			"%s"+
+			"Blocks match again at slot %d\n"+
+			"• Diverged for: %s\n"+
+			"• Mismatches while diverged: %d\n"+
+			"• Time: %s",
+			t.environmentLine(), result.Slot, duration, t.divergedMismatches, result.Time.Format("2006-01-02 15:04:05"))
+		if err := t.postSlackMessage(message); err != nil {
+			t.logger.Error("Failed to send recovery Slack notification", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package qatracker
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithHeartbeat logs a heartbeat every interval, whether or not a comparison ran, so monitoring
+// can tell an idle but healthy tracker from a hung one
+func WithHeartbeat(interval time.Duration) Option {
+	return func(t *Tracker) {
+		t.heartbeatInterval = interval
+	}
+}
+
+// heartbeat logs that the tracker loop is alive and records the last heartbeat time
+func (t *Tracker) heartbeat() {
+	now := time.Now()
+	t.lastHeartbeat.Store(now.Unix())
+	if t.statsCheckpoint != nil {
+		t.statsCheckpoint.RecordHeartbeat(now)
+	}
+
+	t.logger.Info("Heartbeat",
+		zap.Time("last_heartbeat", now),
+		zap.Int("comparisons_in_flight", len(t.comparisonSlots)))
+}
@@ -0,0 +1,74 @@
+package qatracker
+
+import (
+	"strings"
+	"sync"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// linkedBlock is the part of the last compared block of a source needed to check linkage
+type linkedBlock struct {
+	slot      uint64
+	blockhash string
+}
+
+// blockLinkage remembers the last compared block of each source to verify that every block
+// links to it through its parent blockhash
+type blockLinkage struct {
+	mu   sync.Mutex
+	last map[string]linkedBlock
+}
+
+// WithLinkageCheck verifies, within each source, that every compared block's previous blockhash
+// is the blockhash of the previously compared block. It is meant for modes comparing consecutive
+// slots (range and batch), a break then indicates a skipped slot or an unaccounted reorg.
+func WithLinkageCheck() Option {
+	return func(t *Tracker) {
+		t.linkage = &blockLinkage{last: map[string]linkedBlock{}}
+	}
+}
+
+// linkageSource names the source of a block from its artifact prefix, e.g. "firehose"
+func linkageSource(artifactPrefix string) string {
+	return strings.TrimSuffix(artifactPrefix, "_block")
+}
+
+// checkLinkage verifies that block links to the previously compared block of source and logs a
+// break otherwise. Blocks older than the last one (concurrent comparisons) are not checked.
+func (t *Tracker) checkLinkage(source string, block *pbsol.Block) {
+	if t.linkage == nil {
+		return
+	}
+
+	t.linkage.mu.Lock()
+	previous, found := t.linkage.last[source]
+	if found && block.Slot <= previous.slot {
+		t.linkage.mu.Unlock()
+		return
+	}
+	t.linkage.last[source] = linkedBlock{slot: block.Slot, blockhash: block.Blockhash}
+	t.linkage.mu.Unlock()
+
+	if !found || block.PreviousBlockhash == previous.blockhash {
+		return
+	}
+
+	reason := "reorg"
+	if block.ParentSlot != previous.slot {
+		reason = "gap"
+	}
+	t.logger.Warn("Block linkage break, previous blockhash doesn't match the previously compared block",
+		zap.String("source", source),
+		zap.String("reason", reason),
+		zap.Uint64("slot", block.Slot),
+		zap.Uint64("parent_slot", block.ParentSlot),
+		zap.String("previous_blockhash", block.PreviousBlockhash),
+		zap.Uint64("previous_compared_slot", previous.slot),
+		zap.String("previous_compared_blockhash", previous.blockhash))
+
+	if t.statsCheckpoint != nil {
+		t.statsCheckpoint.RecordLinkageBreak(source)
+	}
+}
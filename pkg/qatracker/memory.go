@@ -0,0 +1,70 @@
+package qatracker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// WithMinFreeMemory defers comparisons while the available system memory is below minBytes
+func WithMinFreeMemory(minBytes uint64) Option {
+	return func(t *Tracker) {
+		t.minFreeMemory = minBytes
+	}
+}
+
+// hasEnoughFreeMemory reports whether a comparison can start without risking an OOM kill. It
+// always returns true when the guard is disabled or the available memory can't be read.
+func (t *Tracker) hasEnoughFreeMemory() bool {
+	if t.minFreeMemory == 0 {
+		return true
+	}
+
+	available, err := availableMemory()
+	if err != nil {
+		t.logger.Warn("Unable to read available memory, ignoring --min-free-memory", zap.Error(err))
+		return true
+	}
+
+	if available < t.minFreeMemory {
+		t.logger.Warn("Available memory below minimum, deferring comparison to the next tick",
+			zap.Uint64("available_bytes", available),
+			zap.Uint64("min_free_bytes", t.minFreeMemory))
+		return false
+	}
+	return true
+}
+
+// availableMemory returns the memory available for new allocations without swapping, as
+// reported by MemAvailable in /proc/meminfo
+func availableMemory() (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open /proc/meminfo: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Line format is "MemAvailable:   12345678 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+
+		kilobytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemAvailable value %q: %w", fields[1], err)
+		}
+		return kilobytes * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read /proc/meminfo: %w", err)
+	}
+
+	return 0, fmt.Errorf("MemAvailable not found in /proc/meminfo")
+}
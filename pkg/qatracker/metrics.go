@@ -0,0 +1,121 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// metricsShutdownTimeout bounds how long the metrics server waits for in-flight scrapes on shutdown
+const metricsShutdownTimeout = 5 * time.Second
+
+// Metrics exposes the comparison metrics over HTTP for Prometheus to scrape
+type Metrics struct {
+	listenAddr string
+	server     *http.Server
+
+	comparisons          prometheus.Counter
+	mismatches           prometheus.Counter
+	firehoseFetchLatency prometheus.Histogram
+	rpcFetchLatency      prometheus.Histogram
+	lastComparedSlot     prometheus.Gauge
+}
+
+// NewMetrics creates the comparison metrics served on listenAddr, e.g. ":9102", once started
+func NewMetrics(listenAddr string) *Metrics {
+	m := &Metrics{
+		listenAddr: listenAddr,
+		comparisons: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "solana_block_qa_comparisons_total",
+			Help: "Total number of block comparisons",
+		}),
+		mismatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "solana_block_qa_mismatches_total",
+			Help: "Total number of block comparisons that found a mismatch",
+		}),
+		firehoseFetchLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "solana_block_qa_firehose_fetch_duration_seconds",
+			Help:    "Latency of Firehose head block fetches",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+		}),
+		rpcFetchLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "solana_block_qa_rpc_fetch_duration_seconds",
+			Help:    "Latency of RPC fetcher block fetches",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+		}),
+		lastComparedSlot: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_block_qa_last_compared_slot",
+			Help: "Slot of the last compared block",
+		}),
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m.comparisons, m.mismatches, m.firehoseFetchLatency, m.rpcFetchLatency, m.lastComparedSlot)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	return m
+}
+
+// Start listens on the metrics address and serves metrics in the background, a listen error
+// is returned immediately
+func (m *Metrics) Start(logger *zap.Logger) error {
+	listener, err := net.Listen("tcp", m.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", m.listenAddr, err)
+	}
+
+	logger.Info("Serving Prometheus metrics", zap.String("listen_addr", listener.Addr().String()))
+	go func() {
+		if err := m.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the metrics server, letting in-flight scrapes complete
+func (m *Metrics) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	return m.server.Shutdown(ctx)
+}
+
+// Record counts a comparison result and tracks its slot
+func (m *Metrics) Record(result Result) {
+	m.comparisons.Inc()
+	if !result.Match {
+		m.mismatches.Inc()
+	}
+	m.lastComparedSlot.Set(float64(result.Slot))
+}
+
+// WithMetrics records comparison metrics, served while the tracker runs
+func WithMetrics(metrics *Metrics) Option {
+	return func(t *Tracker) {
+		t.metrics = metrics
+	}
+}
+
+// observeFirehoseFetch records the latency of a Firehose fetch started at start, when metrics are enabled
+func (t *Tracker) observeFirehoseFetch(start time.Time) {
+	if t.metrics != nil {
+		t.metrics.firehoseFetchLatency.Observe(time.Since(start).Seconds())
+	}
+}
+
+// observeRPCFetch records the latency of an RPC fetcher fetch started at start, when metrics are enabled
+func (t *Tracker) observeRPCFetch(start time.Time) {
+	if t.metrics != nil {
+		t.metrics.rpcFetchLatency.Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,101 @@
+package qatracker
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// mismatchRateWindow tracks the outcome of the last N comparisons in a ring buffer
+// and reports when the mismatch rate crosses the configured threshold
+type mismatchRateWindow struct {
+	outcomes   []bool // true means the comparison was a mismatch
+	next       int
+	count      int
+	mismatches int
+	threshold  float64 // percent, e.g. 5 means 5 mismatches per 100 comparisons
+	alerting   bool
+}
+
+func newMismatchRateWindow(size int, threshold float64) *mismatchRateWindow {
+	if size <= 0 {
+		size = 100
+	}
+
+	return &mismatchRateWindow{
+		outcomes:  make([]bool, size),
+		threshold: threshold,
+	}
+}
+
+// record adds a comparison outcome to the window, evicting the oldest one when full
+func (w *mismatchRateWindow) record(mismatch bool) {
+	if w.count == len(w.outcomes) {
+		if w.outcomes[w.next] {
+			w.mismatches--
+		}
+	} else {
+		w.count++
+	}
+
+	w.outcomes[w.next] = mismatch
+	if mismatch {
+		w.mismatches++
+	}
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+// rate returns the mismatch rate as mismatches per 100 comparisons
+func (w *mismatchRateWindow) rate() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return float64(w.mismatches) * 100 / float64(w.count)
+}
+
+// recordMismatchRate records a comparison outcome and sends an alert when the rolling
+// mismatch rate crosses the threshold. The alert fires once when the threshold is
+// crossed and re-arms once the rate drops back below it.
+func (t *Tracker) recordMismatchRate(slot uint64, mismatch bool) {
+	if t.mismatchRate == nil {
+		return
+	}
+
+	w := t.mismatchRate
+	w.record(mismatch)
+	rate := w.rate()
+
+	if rate < w.threshold {
+		if w.alerting {
+			t.logger.Info("Mismatch rate back below threshold",
+				zap.Float64("mismatch_rate", rate),
+				zap.Float64("threshold", w.threshold))
+		}
+		w.alerting = false
+		return
+	}
+
+	if w.alerting {
+		return
+	}
+	w.alerting = true
+
+	t.logger.Warn("Mismatch rate crossed threshold",
+		zap.Uint64("slot", slot),
+		zap.Float64("mismatch_rate", rate),
+		zap.Float64("threshold", w.threshold),
+		zap.Int("window", w.count))
+
+	message := fmt.Sprintf("📈 *Solana Block QA Mismatch Rate Alert* 📈\n"+
+		"%s"+
+		"Mismatch rate reached %.2f%% (threshold %.2f%%)\n"+
+		"• Mismatches: %d of the last %d comparisons\n"+
+		"• Latest slot: %d\n"+
+		"• Time: %s",
+		t.environmentLine(), rate, w.threshold, w.mismatches, w.count, slot, time.Now().Format("2006-01-02 15:04:05"))
+
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send mismatch rate Slack notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,64 @@
+package qatracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// Alert categories of a Firehose block the RPC node has no block for
+const (
+	// missingSlotSkipped means the RPC node reports the slot as skipped by its leader
+	missingSlotSkipped = "rpc_skipped"
+	// missingSlotNotFound means the RPC node answered with an error for the slot while Firehose
+	// served real data, a strong indicator of a Firehose phantom block
+	missingSlotNotFound = "rpc_not_found"
+)
+
+// missingSlotCategory returns the alert category of an RPC fetch error for a slot Firehose
+// served a block for, or "" when the error doesn't say the slot has no block
+func missingSlotCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrSkipped):
+		return missingSlotSkipped
+	case errors.Is(err, ErrNotFound):
+		return missingSlotNotFound
+	default:
+		return ""
+	}
+}
+
+// reportMissingSlot alerts on a block served by Firehose for a slot the RPC node has no block
+// for. A slot reported as skipped is logged, any other answer is sent as a critical alert.
+func (t *Tracker) reportMissingSlot(firehoseBlock *pbsol.Block, category string, rpcErr error) {
+	if category == missingSlotSkipped {
+		t.logger.Warn("Firehose served a block for a slot the RPC node reports as skipped",
+			zap.Uint64("slot", firehoseBlock.Slot),
+			zap.String("category", category),
+			zap.String("firehose_block_hash", firehoseBlock.Blockhash))
+		return
+	}
+
+	t.logger.Error("Firehose served a block for a slot the RPC node reports as non-existent, possible phantom block",
+		zap.Uint64("slot", firehoseBlock.Slot),
+		zap.String("category", category),
+		zap.String("firehose_block_hash", firehoseBlock.Blockhash),
+		zap.Error(rpcErr))
+
+	message := fmt.Sprintf("🛑 *Solana Block QA Critical Alert: possible Firehose phantom block* 🛑\n"+
+		"%s"+
+		"Firehose served a block at slot %d but the RPC node reports the slot as non-existent\n"+
+		"• Category: `%s`\n"+
+		"• Firehose block hash: `%s`\n"+
+		"• Firehose transactions: %d\n"+
+		"• RPC error: `%s`\n"+
+		"• Time: %s",
+		t.environmentLine(), firehoseBlock.Slot, category, firehoseBlock.Blockhash, len(firehoseBlock.Transactions), rpcErr, time.Now().Format("2006-01-02 15:04:05"))
+
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send phantom block Slack notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,50 @@
+package qatracker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NetworkEndpoints holds the default endpoints of a Solana network, an empty endpoint has no
+// public default and must be given explicitly
+type NetworkEndpoints struct {
+	Firehose string
+	RPC      string
+}
+
+// DefaultNetworkEndpoints maps each network to the endpoints used when none is given
+var DefaultNetworkEndpoints = map[string]NetworkEndpoints{
+	"mainnet": {Firehose: "mainnet.sol.streamingfast.io:443", RPC: "https://api.mainnet-beta.solana.com"},
+	"devnet":  {Firehose: "devnet.sol.streamingfast.io:443", RPC: "https://api.devnet.solana.com"},
+	"testnet": {Firehose: "", RPC: "https://api.testnet.solana.com"},
+}
+
+// ParseNetwork validates and returns the network for the given value, mainnet-beta is accepted
+// as an alias of mainnet
+func ParseNetwork(value string) (string, error) {
+	switch network := strings.ToLower(value); network {
+	case "mainnet", "devnet", "testnet":
+		return network, nil
+	case "mainnet-beta":
+		return "mainnet", nil
+	default:
+		return "", fmt.Errorf("invalid network %q (valid values: mainnet, devnet, testnet)", value)
+	}
+}
+
+// EndpointNetwork returns the network an endpoint URL names, e.g. devnet for
+// devnet.sol.streamingfast.io:443, or "" when it names none
+func EndpointNetwork(endpoint string) string {
+	endpoint = strings.ToLower(endpoint)
+	for _, network := range []string{"mainnet", "devnet", "testnet"} {
+		if strings.Contains(endpoint, network) {
+			return network
+		}
+	}
+	return ""
+}
+
+// networkLabel returns the network shown in notifications, mainnet-beta shows as mainnet
+func (t *Tracker) networkLabel() string {
+	return strings.TrimSuffix(t.fetcherConfig.Network, "-beta")
+}
@@ -0,0 +1,222 @@
+package qatracker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// notifyTimeout bounds the delivery of a mismatch event to a single notifier
+const notifyTimeout = 10 * time.Second
+
+// MismatchEvent describes a block mismatch delivered to the notifiers
+type MismatchEvent struct {
+	Slot               uint64 `json:"slot"`
+	FirehoseChecksum   string `json:"firehose_checksum"`
+	RPCFetcherChecksum string `json:"rpc_fetcher_checksum"`
+	FirehoseFile       string `json:"firehose_file"`
+	RPCFetcherFile     string `json:"rpc_fetcher_file"`
+	// DiffFile is the field diffs file, empty unless written
+	DiffFile string `json:"diff_file,omitempty"`
+	// Environment is the output prefix identifying the deployment, empty when not set
+	Environment   string      `json:"environment,omitempty"`
+	Network       string      `json:"network"`
+	EpochBoundary bool        `json:"epoch_boundary"`
+	FieldDiffs    []FieldDiff `json:"field_diffs,omitempty"`
+	// DifferingTransactions are the first transactions whose checksums differ, out of
+	// DifferingTransactionCount
+	DifferingTransactions     []TransactionDiff `json:"differing_transactions,omitempty"`
+	DifferingTransactionCount int               `json:"differing_transaction_count,omitempty"`
+	// Providers compares the Firehose block with every RPC provider when several are configured
+	Providers []ProviderResult `json:"providers,omitempty"`
+	Time      time.Time        `json:"time"`
+}
+
+// Notifier is a sink mismatch events are delivered to
+type Notifier interface {
+	Notify(ctx context.Context, event MismatchEvent) error
+}
+
+// Resolver is implemented by the notifiers that resolve the alerts they raised once blocks
+// match again, it's called with the slot of every matching comparison
+type Resolver interface {
+	Resolve(ctx context.Context, slot uint64) error
+}
+
+// WithNotifier adds a notifier mismatch events are delivered to, next to Slack
+func WithNotifier(notifier Notifier) Option {
+	return func(t *Tracker) {
+		t.notifiers = append(t.notifiers, notifier)
+	}
+}
+
+// notifyMismatch delivers a mismatch event to every notifier, a failing notifier doesn't
+// prevent delivery to the others
+func (t *Tracker) notifyMismatch(event MismatchEvent) {
+	for _, notifier := range t.notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := notifier.Notify(ctx, event); err != nil {
+			t.logger.Error("Failed to send mismatch notification", zap.String("notifier", fmt.Sprintf("%T", notifier)), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// notifyMatch lets every notifier implementing Resolver resolve the alerts the matching slot
+// closes
+func (t *Tracker) notifyMatch(slot uint64) {
+	for _, notifier := range t.notifiers {
+		resolver, ok := notifier.(Resolver)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := resolver.Resolve(ctx, slot); err != nil {
+			t.logger.Error("Failed to resolve mismatch notification", zap.String("notifier", fmt.Sprintf("%T", notifier)), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// mismatchEvent builds the event of a mismatch at slot
+func (t *Tracker) mismatchEvent(slot uint64, firehoseSum, rpcSum, firehoseFilePath, rpcFetcherFilePath string, diffs []FieldDiff, epochBoundary bool) MismatchEvent {
+	return MismatchEvent{
+		Slot:               slot,
+		FirehoseChecksum:   firehoseSum,
+		RPCFetcherChecksum: rpcSum,
+		FirehoseFile:       firehoseFilePath,
+		RPCFetcherFile:     rpcFetcherFilePath,
+		Environment:        strings.TrimRight(t.outputPrefix, "-_."),
+		Network:            t.networkLabel(),
+		EpochBoundary:      epochBoundary,
+		FieldDiffs:         diffs,
+		Time:               time.Now(),
+	}
+}
+
+// SlackNotifier posts messages to a Slack webhook, nothing is posted when the webhook URL is empty
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	logger     *zap.Logger
+}
+
+// NewSlackNotifier creates a SlackNotifier posting to channel through webhookURL
+func NewSlackNotifier(webhookURL, channel string, logger *zap.Logger) *SlackNotifier {
+	return &SlackNotifier{webhookURL: webhookURL, channel: channel, logger: logger}
+}
+
+// Notify posts the mismatch alert, listing the differing field paths
+func (n *SlackNotifier) Notify(ctx context.Context, event MismatchEvent) error {
+	message := fmt.Sprintf("🚨 *Solana Block QA Alert* 🚨\n"+
+		"%s"+
+		"Block differences detected at slot %d\n"+
+		"%s"+
+		"%s"+
+		"%s"+
+		"%s"+
+		"%s"+
+		"• Firehose checksum: `%s`\n"+
+		"• RPC Fetcher checksum: `%s`\n"+
+		"• Firehose artifact: %s\n"+
+		"• RPC Fetcher artifact: %s\n"+
+		"%s"+
+		"• Time: %s",
+		environmentLine(event.Environment, event.Network), event.Slot, epochBoundaryLine(event.Slot, event.EpochBoundary), highSeverityLine(highSeverityPaths(event.FieldDiffs)),
+		differingPathsLine(event.FieldDiffs), differingTransactionsLine(event.DifferingTransactions, event.DifferingTransactionCount), providersLine(event.Providers), event.FirehoseChecksum, event.RPCFetcherChecksum, slackArtifact(event.FirehoseFile), slackArtifact(event.RPCFetcherFile), diffFileLine(event.DiffFile), event.Time.Format("2006-01-02 15:04:05"))
+
+	return n.post(ctx, message)
+}
+
+// diffFileLine returns the notification line linking the field diffs file, or an empty string
+// when it wasn't written
+func diffFileLine(diffFile string) string {
+	if diffFile == "" {
+		return ""
+	}
+	return fmt.Sprintf("• Diff file: %s\n", slackArtifact(diffFile))
+}
+
+// slackArtifact formats an artifact location for Slack, HTTP URLs (public or signed links)
+// are left clickable while paths and object URLs are shown as code
+func slackArtifact(location string) string {
+	if strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://") {
+		return "<" + location + "|" + path.Base(strings.SplitN(location, "?", 2)[0]) + ">"
+	}
+	return "`" + location + "`"
+}
+
+// post posts a raw text message to the webhook
+func (n *SlackNotifier) post(ctx context.Context, message string) error {
+	if n.webhookURL == "" {
+		n.logger.Info("SLACK_WEBHOOK_URL not set, skipping Slack notification")
+		return nil
+	}
+
+	channel := n.channel
+	if channel == "" {
+		channel = "#general" // default channel
+	}
+
+	payload := slack.WebhookMessage{
+		Channel:   channel,
+		Username:  "Solana Block QA Tracker",
+		IconEmoji: ":warning:",
+		Text:      message,
+	}
+
+	err := slack.PostWebhookContext(ctx, n.webhookURL, &payload)
+	if err != nil {
+		return fmt.Errorf("failed to send Slack notification: %w", err)
+	}
+
+	n.logger.Info("Slack notification sent", zap.String("channel", channel))
+	return nil
+}
+
+// WebhookNotifier posts mismatch events as JSON to a generic webhook
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+	// Renders the JSON payload from the event, the event itself is posted when nil
+	template *template.Template
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{}}
+}
+
+// Notify posts the event as a JSON object, or the payload rendered by the template, any
+// non-2xx status is an error
+func (n *WebhookNotifier) Notify(ctx context.Context, event MismatchEvent) error {
+	body, err := n.payload(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
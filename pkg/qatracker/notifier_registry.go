@@ -0,0 +1,156 @@
+package qatracker
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// NotifierFactory builds a notifier backend from the key=value settings of its spec
+type NotifierFactory func(settings map[string]string, logger *zap.Logger) (Notifier, error)
+
+var (
+	notifierFactoriesMu sync.RWMutex
+	notifierFactories   = map[string]NotifierFactory{}
+)
+
+func init() {
+	RegisterNotifier("slack", newSlackNotifierFromSettings)
+	RegisterNotifier("webhook", newWebhookNotifierFromSettings)
+	RegisterNotifier("email", newEmailAlertNotifierFromSettings)
+	RegisterNotifier("pagerduty", newPagerDutyNotifierFromSettings)
+}
+
+// RegisterNotifier makes a notifier backend available under name, it panics when name is
+// already registered. Programs embedding the tracker call it from an init function to add
+// their own backends.
+func RegisterNotifier(name string, factory NotifierFactory) {
+	notifierFactoriesMu.Lock()
+	defer notifierFactoriesMu.Unlock()
+
+	name = strings.ToLower(name)
+	if _, found := notifierFactories[name]; found {
+		panic(fmt.Sprintf("notifier %q registered twice", name))
+	}
+	notifierFactories[name] = factory
+}
+
+// RegisteredNotifiers returns the sorted names of the registered notifier backends
+func RegisteredNotifiers() []string {
+	notifierFactoriesMu.RLock()
+	defer notifierFactoriesMu.RUnlock()
+
+	names := make([]string, 0, len(notifierFactories))
+	for name := range notifierFactories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseNotifier builds the notifier described by spec, a registered backend name optionally
+// followed by a colon and comma separated key=value settings, e.g.
+// "slack:webhook-url=https://hooks.slack.com/services/...,channel=#qa"
+func ParseNotifier(spec string, logger *zap.Logger) (Notifier, error) {
+	name, rawSettings, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	notifierFactoriesMu.RLock()
+	factory, found := notifierFactories[name]
+	notifierFactoriesMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown notifier %q (valid values: %s)", name, strings.Join(RegisteredNotifiers(), ", "))
+	}
+
+	settings := map[string]string{}
+	if strings.TrimSpace(rawSettings) != "" {
+		for _, pair := range strings.Split(rawSettings, ",") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid %s notifier setting %q (expected key=value)", name, pair)
+			}
+			settings[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(val)
+		}
+	}
+
+	notifier, err := factory(settings, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s notifier: %w", name, err)
+	}
+	return notifier, nil
+}
+
+// checkNotifierSettings returns an error when settings hold a key outside of valid
+func checkNotifierSettings(settings map[string]string, valid ...string) error {
+	for key := range settings {
+		if !slices.Contains(valid, key) {
+			return fmt.Errorf("unknown key %q (valid keys: %s)", key, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// newSlackNotifierFromSettings builds a SlackNotifier from the webhook-url and channel settings
+func newSlackNotifierFromSettings(settings map[string]string, logger *zap.Logger) (Notifier, error) {
+	if err := checkNotifierSettings(settings, "webhook-url", "channel"); err != nil {
+		return nil, err
+	}
+	if settings["webhook-url"] == "" {
+		return nil, fmt.Errorf("webhook-url is required")
+	}
+	return NewSlackNotifier(settings["webhook-url"], settings["channel"], logger), nil
+}
+
+// newWebhookNotifierFromSettings builds a WebhookNotifier from the url setting, rendering its
+// payload with the template file of the template setting when set
+func newWebhookNotifierFromSettings(settings map[string]string, logger *zap.Logger) (Notifier, error) {
+	if err := checkNotifierSettings(settings, "url", "template"); err != nil {
+		return nil, err
+	}
+	if settings["url"] == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if settings["template"] == "" {
+		return NewWebhookNotifier(settings["url"]), nil
+	}
+
+	tmpl, err := ParseWebhookTemplate(settings["template"])
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplatedWebhookNotifier(settings["url"], tmpl), nil
+}
+
+// newEmailAlertNotifierFromSettings builds an EmailAlertNotifier from the smtp settings, the
+// recipients are separated by semicolons since commas separate the settings
+func newEmailAlertNotifierFromSettings(settings map[string]string, logger *zap.Logger) (Notifier, error) {
+	if err := checkNotifierSettings(settings, "host", "port", "username", "password", "from", "to"); err != nil {
+		return nil, err
+	}
+
+	config := SMTPConfig{
+		Host:     settings["host"],
+		Port:     587,
+		Username: settings["username"],
+		Password: settings["password"],
+		From:     settings["from"],
+	}
+	if value := settings["port"]; value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", value, err)
+		}
+		config.Port = port
+	}
+	for _, to := range strings.Split(settings["to"], ";") {
+		if to = strings.TrimSpace(to); to != "" {
+			config.To = append(config.To, to)
+		}
+	}
+
+	return NewEmailAlertNotifier(config)
+}
@@ -0,0 +1,52 @@
+package qatracker
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+)
+
+// WithNormalizeOrder makes the checksum comparison ignore transaction ordering, a block whose
+// transactions only differ by position is then reported as a match (ordering is still logged)
+func WithNormalizeOrder() Option {
+	return func(t *Tracker) {
+		t.normalizeOrder = true
+	}
+}
+
+// transactionSignature returns the first (identifying) signature of a transaction, or nil
+func transactionSignature(trx *pbsol.ConfirmedTransaction) []byte {
+	signatures := trx.GetTransaction().GetSignatures()
+	if len(signatures) == 0 {
+		return nil
+	}
+	return signatures[0]
+}
+
+// sameTransactionOrder reports whether both blocks have the same transaction signature at
+// every index
+func sameTransactionOrder(a, b *pbsol.Block) bool {
+	return slices.EqualFunc(a.Transactions, b.Transactions, func(x, y *pbsol.ConfirmedTransaction) bool {
+		return bytes.Equal(transactionSignature(x), transactionSignature(y))
+	})
+}
+
+// calculateNormalizedChecksum calculates the sanitized checksum of a block over the given scope
+// with its transactions sorted by signature. The block itself keeps its original order.
+func calculateNormalizedChecksum(block *pbsol.Block, scope ChecksumScope, fields IgnoredFields) (string, error) {
+	original := block.Transactions
+	defer func() { block.Transactions = original }()
+
+	block.Transactions = slices.Clone(original)
+	slices.SortStableFunc(block.Transactions, func(x, y *pbsol.ConfirmedTransaction) int {
+		return bytes.Compare(transactionSignature(x), transactionSignature(y))
+	})
+
+	checksum, err := calculateScopedChecksum(block, scope, fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate normalized checksum: %w", err)
+	}
+	return checksum, nil
+}
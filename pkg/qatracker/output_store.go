@@ -0,0 +1,66 @@
+package qatracker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/streamingfast/dstore"
+)
+
+// outputStoreWriteTimeout bounds the upload of a single artifact to the output store
+const outputStoreWriteTimeout = 2 * time.Minute
+
+// NewOutputStore opens the dstore location artifacts are written to (e.g. s3://bucket/qa),
+// existing artifacts of a slot compared again are overwritten
+func NewOutputStore(url string) (dstore.Store, error) {
+	store, err := dstore.NewStore(url, "", "", true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output store %s: %w", url, err)
+	}
+	return store, nil
+}
+
+// WithOutputStore writes artifacts to a dstore location instead of the output directory, so
+// they outlive an ephemeral container. Notifications then show the object URLs.
+func WithOutputStore(store dstore.Store) Option {
+	return func(t *Tracker) {
+		t.outputStore = store
+	}
+}
+
+// artifactLocation returns where the artifact file named filename is written: its path under the
+// output directory, or its object URL under the output store when set
+func (t *Tracker) artifactLocation(filename string) string {
+	if t.outputStore != nil {
+		return t.outputStore.ObjectURL(filename)
+	}
+	return filepath.Join(t.outputDir, filename)
+}
+
+// prepareOutput creates the output directory, the output store needs no preparation
+func (t *Tracker) prepareOutput() error {
+	if t.outputStore != nil {
+		return nil
+	}
+	if err := os.MkdirAll(t.outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory %s: %w", t.outputDir, err)
+	}
+	return nil
+}
+
+// writeArtifactFile writes data to the artifact at location, as returned by artifactLocation.
+// Under the output store, the object is named after the last element of its URL.
+func (t *Tracker) writeArtifactFile(location string, data []byte) error {
+	if t.outputStore == nil {
+		return os.WriteFile(location, data, 0644)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), outputStoreWriteTimeout)
+	defer cancel()
+	return t.outputStore.WriteObject(ctx, path.Base(location), bytes.NewReader(data))
+}
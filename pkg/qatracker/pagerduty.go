@@ -0,0 +1,199 @@
+package qatracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyDedup selects which mismatches share a PagerDuty incident
+type PagerDutyDedup string
+
+const (
+	// PagerDutyDedupStream raises a single incident for the stream, resolved by the next match
+	PagerDutyDedupStream PagerDutyDedup = "stream"
+	// PagerDutyDedupSlot raises an incident per slot, resolved by a later match of the same slot
+	PagerDutyDedupSlot PagerDutyDedup = "slot"
+)
+
+// ParsePagerDutyDedup parses a --pagerduty-dedup value
+func ParsePagerDutyDedup(value string) (PagerDutyDedup, error) {
+	switch dedup := PagerDutyDedup(strings.ToLower(value)); dedup {
+	case PagerDutyDedupStream, PagerDutyDedupSlot:
+		return dedup, nil
+	default:
+		return "", fmt.Errorf("invalid PagerDuty dedup %q (valid values: stream, slot)", value)
+	}
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents through the Events API v2 on mismatches and
+// resolves them once comparisons match again
+type PagerDutyNotifier struct {
+	routingKey string
+	dedup      PagerDutyDedup
+	url        string
+	client     *http.Client
+	logger     *zap.Logger
+
+	mu sync.Mutex
+	// Dedup keys of the incidents triggered and not resolved yet, by slot (a single entry under
+	// slot 0 with the stream dedup)
+	open map[uint64]string
+}
+
+// NewPagerDutyNotifier creates a PagerDutyNotifier sending events with routingKey, the
+// integration key of a PagerDuty Events API v2 service
+func NewPagerDutyNotifier(routingKey string, dedup PagerDutyDedup, logger *zap.Logger) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		dedup:      dedup,
+		url:        pagerDutyEventsURL,
+		client:     &http.Client{},
+		logger:     logger,
+		open:       map[uint64]string{},
+	}
+}
+
+// pagerDutyEvent is an Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string        `json:"summary"`
+	Source        string        `json:"source"`
+	Severity      string        `json:"severity"`
+	Timestamp     string        `json:"timestamp"`
+	Component     string        `json:"component"`
+	CustomDetails MismatchEvent `json:"custom_details"`
+}
+
+// Notify triggers the incident of the mismatch, further mismatches of an open incident are
+// added to it by PagerDuty
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event MismatchEvent) error {
+	source := event.Network
+	if event.Environment != "" {
+		source = event.Environment + "/" + event.Network
+	}
+
+	key := n.openKey(event.Slot)
+	if key == "" {
+		key = "solana-block-qa/" + source
+		if n.dedup == PagerDutyDedupSlot {
+			key += fmt.Sprintf("/%d", event.Slot)
+		}
+	}
+
+	err := n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		DedupKey:    key,
+		Payload: &pagerDutyPayload{
+			Summary:       fmt.Sprintf("Solana block mismatch between Firehose and RPC Fetcher at slot %d (%s)", event.Slot, source),
+			Source:        source,
+			Severity:      "error",
+			Timestamp:     event.Time.UTC().Format(time.RFC3339),
+			Component:     "solana-block-qa-tracker",
+			CustomDetails: event,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.open[n.incidentSlot(event.Slot)] = key
+	n.mu.Unlock()
+	return nil
+}
+
+// Resolve resolves the incident the matching slot closes, if any is open
+func (n *PagerDutyNotifier) Resolve(ctx context.Context, slot uint64) error {
+	key := n.openKey(slot)
+	if key == "" {
+		return nil
+	}
+
+	if err := n.send(ctx, pagerDutyEvent{RoutingKey: n.routingKey, EventAction: "resolve", DedupKey: key}); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	delete(n.open, n.incidentSlot(slot))
+	n.mu.Unlock()
+	n.logger.Info("PagerDuty incident resolved", zap.String("dedup_key", key), zap.Uint64("slot", slot))
+	return nil
+}
+
+// incidentSlot returns the key of the incident of slot in open
+func (n *PagerDutyNotifier) incidentSlot(slot uint64) uint64 {
+	if n.dedup == PagerDutyDedupSlot {
+		return slot
+	}
+	return 0
+}
+
+// openKey returns the dedup key of the open incident of slot, or an empty string
+func (n *PagerDutyNotifier) openKey(slot uint64) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.open[n.incidentSlot(slot)]
+}
+
+// send posts an event to the Events API, which answers 202 once the event is accepted
+func (n *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create PagerDuty request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send PagerDuty %s event: %w", event.EventAction, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty returned status %s for %s event", resp.Status, event.EventAction)
+	}
+	return nil
+}
+
+// newPagerDutyNotifierFromSettings builds a PagerDutyNotifier from the routing-key and dedup
+// settings
+func newPagerDutyNotifierFromSettings(settings map[string]string, logger *zap.Logger) (Notifier, error) {
+	if err := checkNotifierSettings(settings, "routing-key", "dedup"); err != nil {
+		return nil, err
+	}
+	if settings["routing-key"] == "" {
+		return nil, fmt.Errorf("routing-key is required")
+	}
+
+	dedup := PagerDutyDedupStream
+	if value := settings["dedup"]; value != "" {
+		var err error
+		if dedup, err = ParsePagerDutyDedup(value); err != nil {
+			return nil, err
+		}
+	}
+	return NewPagerDutyNotifier(settings["routing-key"], dedup, logger), nil
+}
@@ -0,0 +1,115 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mostynb/go-grpc-compression/zstd"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/oauth"
+)
+
+// PeerFirehose is a second Firehose endpoint, e.g. a partner's deployment, serving the blocks
+// our Firehose blocks are compared against
+type PeerFirehose struct {
+	endpoint string
+	conn     *grpc.ClientConn
+	client   pbfirehose.StreamClient
+	callOpts []grpc.CallOption
+}
+
+// NewPeerFirehose connects to the Firehose endpoint, authenticating with apiToken, or apiKey
+// when no token is set
+func NewPeerFirehose(endpoint, apiToken, apiKey string) (*PeerFirehose, error) {
+	conn, err := dialFirehose(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to peer Firehose %s: %w", endpoint, err)
+	}
+
+	var callOpts []grpc.CallOption
+	if apiToken != "" {
+		credentials := oauth.NewOauthAccess(&oauth2.Token{AccessToken: apiToken, TokenType: "Bearer"})
+		callOpts = append(callOpts, grpc.PerRPCCredentials(credentials))
+	} else if apiKey != "" {
+		callOpts = append(callOpts, grpc.PerRPCCredentials(&ApiKeyAuth{ApiKey: apiKey}))
+	}
+	callOpts = append(callOpts, grpc.UseCompressor(zstd.Name))
+
+	return &PeerFirehose{
+		endpoint: endpoint,
+		conn:     conn,
+		client:   pbfirehose.NewStreamClient(conn),
+		callOpts: callOpts,
+	}, nil
+}
+
+// WithPeerFirehose compares every Firehose block with the block of the same slot served by the
+// peer Firehose endpoint instead of the RPC fetcher block
+func WithPeerFirehose(peer *PeerFirehose) Option {
+	return func(t *Tracker) {
+		t.peerFirehose = peer
+	}
+}
+
+// Close closes the connection to the peer endpoint
+func (p *PeerFirehose) Close() error {
+	return p.conn.Close()
+}
+
+// fetchPeerFirehoseBlock fetches the block at slot from the peer Firehose endpoint, bounded by
+// the Firehose fetch timeout, and computes its sanitized checksum. The peer streams blocks of the
+// same commitment as our head blocks, a stream ending without the slot means it was skipped.
+func (t *Tracker) fetchPeerFirehoseBlock(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	fetchCtx, cancel := withFetchTimeout(ctx, t.firehoseFetchTimeout)
+	defer cancel()
+
+	req := &pbfirehose.Request{
+		StartBlockNum:   int64(slot),
+		StopBlockNum:    slot,
+		FinalBlocksOnly: t.fetcherConfig.finalBlocksOnly(),
+	}
+
+	stream, err := t.peerFirehose.client.Blocks(fetchCtx, req, t.peerFirehose.callOpts...)
+	if err != nil {
+		return nil, "", classifyFirehoseError(err)
+	}
+	resp, err := t.recvWithWatchdog(stream, cancel)
+	if errors.Is(err, io.EOF) {
+		return nil, "", fmt.Errorf("%w: peer Firehose stream ended without slot %d, it was likely skipped", ErrSkipped, slot)
+	}
+	if err != nil {
+		if fetchTimedOut(ctx, fetchCtx) {
+			return nil, "", fmt.Errorf("%w: peer Firehose fetch timed out after %s: %w", ErrNetwork, t.firehoseFetchTimeout, err)
+		}
+		return nil, "", classifyFirehoseError(err)
+	}
+
+	block, checksum, err := t.decodeFirehoseResponse(resp)
+	if err != nil {
+		return nil, "", err
+	}
+	if block.Slot != slot {
+		t.takeRawBytes(block)
+		return nil, "", fmt.Errorf("%w: peer Firehose returned slot %d but %d was requested, slot %d was likely skipped", ErrSkipped, block.Slot, slot, slot)
+	}
+	return block, checksum, nil
+}
+
+// compareWithPeerFirehose compares the Firehose block with the block of the same slot served by
+// the peer Firehose endpoint
+func (t *Tracker) compareWithPeerFirehose(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string) (*Result, error) {
+	t.logger.Info("Fetching block from peer Firehose", zap.Uint64("slot", firehoseBlock.Slot), zap.String("endpoint", t.peerFirehose.endpoint))
+	peerBlock, peerBlockSum, err := t.fetchPeerFirehoseBlock(ctx, firehoseBlock.Slot)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching block from peer Firehose: %w", err)
+	}
+	defer t.takeRawBytes(peerBlock)
+
+	return t.compareFetchedBlocks(firehoseBlock, firehoseBlockSum, peerBlock, peerBlockSum, "firehose_block", "peer_firehose_block", nil)
+}
@@ -0,0 +1,160 @@
+package qatracker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// resultsWriteTimeout bounds how long a batch insert may take so a slow database can't stall comparisons
+const resultsWriteTimeout = 30 * time.Second
+
+// postgresMigration creates the results table on first connect, it is safe to run on every start
+const postgresMigration = `
+CREATE TABLE IF NOT EXISTS comparison_results (
+	id                   BIGSERIAL PRIMARY KEY,
+	environment          TEXT        NOT NULL DEFAULT '',
+	slot                 BIGINT      NOT NULL,
+	firehose_checksum    TEXT        NOT NULL,
+	rpc_fetcher_checksum TEXT        NOT NULL,
+	match                BOOLEAN     NOT NULL,
+	order_match          BOOLEAN     NOT NULL,
+	diff_categories      TEXT[]      NOT NULL DEFAULT '{}',
+	firehose_file        TEXT        NOT NULL DEFAULT '',
+	rpc_fetcher_file     TEXT        NOT NULL DEFAULT '',
+	compared_at          TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS comparison_results_slot_idx ON comparison_results (slot);
+CREATE INDEX IF NOT EXISTS comparison_results_compared_at_idx ON comparison_results (environment, compared_at);
+`
+
+// PostgresSink writes comparison results to a Postgres table so several tracker instances can
+// report into one queryable store
+type PostgresSink struct {
+	db          *sql.DB
+	environment string
+}
+
+// NewPostgresSink connects to the database at dsn and creates the results table if needed.
+// Every row is tagged with environment (the output prefix) to keep instances apart.
+func NewPostgresSink(ctx context.Context, dsn, environment string) (*PostgresSink, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Postgres connection: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, postgresMigration); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create results table: %w", err)
+	}
+
+	return &PostgresSink{db: db, environment: environment}, nil
+}
+
+// Insert writes all results in a single multi-row INSERT
+func (s *PostgresSink) Insert(ctx context.Context, results []Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	const columns = 10
+	placeholders := make([]string, 0, len(results))
+	args := make([]any, 0, len(results)*columns)
+	for i, result := range results {
+		categories := result.DiffCategories
+		if categories == nil {
+			categories = []string{}
+		}
+
+		values := make([]string, columns)
+		for j := range values {
+			values[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(values, ", ")+")")
+
+		args = append(args,
+			s.environment,
+			int64(result.Slot),
+			result.FirehoseChecksum,
+			result.RPCFetcherChecksum,
+			result.Match,
+			result.OrderMatch,
+			pq.Array(categories),
+			result.FirehoseFile,
+			result.RPCFetcherFile,
+			result.Time,
+		)
+	}
+
+	query := "INSERT INTO comparison_results (environment, slot, firehose_checksum, rpc_fetcher_checksum, match, order_match, diff_categories, firehose_file, rpc_fetcher_file, compared_at) VALUES " +
+		strings.Join(placeholders, ", ")
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert %d comparison results: %w", len(results), err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying database connection pool
+func (s *PostgresSink) Close() error {
+	return s.db.Close()
+}
+
+// ResultsSink is a store comparison results are inserted into in batches
+type ResultsSink interface {
+	Insert(ctx context.Context, results []Result) error
+	Close() error
+}
+
+// WithResultsSink stores every comparison result in sink, by default each result is written
+// as soon as it is produced
+func WithResultsSink(sink ResultsSink) Option {
+	return func(t *Tracker) {
+		t.resultsSink = sink
+	}
+}
+
+// WithResultsBatchSize sets how many results are buffered per insert into the results sink
+func WithResultsBatchSize(batchSize int) Option {
+	return func(t *Tracker) {
+		t.resultsBatchSize = max(batchSize, 1)
+	}
+}
+
+// bufferResult queues a result for the results sink and flushes once a full batch is pending
+func (t *Tracker) bufferResult(result Result) {
+	if t.resultsSink == nil {
+		return
+	}
+
+	t.pendingResults = append(t.pendingResults, result)
+	if len(t.pendingResults) >= t.resultsBatchSize {
+		t.flushResults()
+	}
+}
+
+// flushResults writes the pending results to the results sink. Failed batches are logged and
+// dropped so a database outage doesn't grow the buffer without bound.
+func (t *Tracker) flushResults() {
+	if t.resultsSink == nil || len(t.pendingResults) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resultsWriteTimeout)
+	defer cancel()
+
+	if err := t.resultsSink.Insert(ctx, t.pendingResults); err != nil {
+		t.logger.Error("Failed to store comparison results", zap.Int("count", len(t.pendingResults)), zap.Error(err))
+	}
+	t.pendingResults = t.pendingResults[:0]
+}
@@ -0,0 +1,121 @@
+package qatracker
+
+import (
+	"context"
+	"sync"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// prefetchedBlock is an RPC fetcher block fetched ahead of its comparison, done is closed once
+// the fetch completed
+type prefetchedBlock struct {
+	done     chan struct{}
+	block    *pbsol.Block
+	checksum string
+	err      error
+}
+
+// rpcPrefetcher fetches the RPC fetcher blocks of the next slots of a stream while the current
+// slot is compared, holding at most depth blocks
+type rpcPrefetcher struct {
+	depth int
+
+	mu      sync.Mutex
+	pending map[uint64]*prefetchedBlock
+}
+
+// WithPrefetch makes streamed comparisons (batch and range modes) fetch the RPC fetcher blocks
+// of up to depth upcoming slots while the current one is compared. Prefetches take comparison
+// slots, so they only run with spare --max-concurrent-comparisons capacity.
+func WithPrefetch(depth int) Option {
+	return func(t *Tracker) {
+		t.prefetcher = &rpcPrefetcher{depth: depth, pending: map[uint64]*prefetchedBlock{}}
+	}
+}
+
+// prefetchRPCBlocks starts fetching the RPC fetcher blocks of the slots following nextSlot, up
+// to the prefetch depth and stopSlot. A slot is passed over when no comparison slot is free.
+func (t *Tracker) prefetchRPCBlocks(ctx context.Context, nextSlot, stopSlot uint64) {
+	if t.prefetcher == nil {
+		return
+	}
+
+	p := t.prefetcher
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Slots before nextSlot were skipped by the stream, their blocks won't be used
+	for slot, prefetched := range p.pending {
+		if slot < nextSlot {
+			delete(p.pending, slot)
+			go t.releasePrefetched(prefetched)
+		}
+	}
+
+	for slot := nextSlot; slot <= stopSlot && slot < nextSlot+uint64(p.depth); slot++ {
+		if _, found := p.pending[slot]; found {
+			continue
+		}
+		if !t.tryAcquireComparison() {
+			return
+		}
+
+		prefetched := &prefetchedBlock{done: make(chan struct{})}
+		p.pending[slot] = prefetched
+		go func(slot uint64) {
+			defer close(prefetched.done)
+			defer t.releaseComparison()
+
+			t.logger.Debug("Prefetching RPC fetcher block", zap.Uint64("slot", slot))
+			prefetched.block, prefetched.checksum, prefetched.err = t.fetchBlockWithRPCFetcher(ctx, slot)
+		}(slot)
+	}
+}
+
+// fetchRPCFetcherBlock returns the RPC fetcher block of slot, from the prefetch buffer when it
+// was prefetched
+func (t *Tracker) fetchRPCFetcherBlock(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	if t.prefetcher != nil {
+		t.prefetcher.mu.Lock()
+		prefetched, found := t.prefetcher.pending[slot]
+		delete(t.prefetcher.pending, slot)
+		t.prefetcher.mu.Unlock()
+
+		if found {
+			select {
+			case <-prefetched.done:
+				return prefetched.block, prefetched.checksum, prefetched.err
+			case <-ctx.Done():
+				go t.releasePrefetched(prefetched)
+				return nil, "", ctx.Err()
+			}
+		}
+	}
+
+	return t.fetchBlockWithRPCFetcher(ctx, slot)
+}
+
+// discardPrefetched drops every prefetched block, e.g. when the stream they were fetched for ends
+func (t *Tracker) discardPrefetched() {
+	if t.prefetcher == nil {
+		return
+	}
+
+	t.prefetcher.mu.Lock()
+	defer t.prefetcher.mu.Unlock()
+
+	for slot, prefetched := range t.prefetcher.pending {
+		delete(t.prefetcher.pending, slot)
+		go t.releasePrefetched(prefetched)
+	}
+}
+
+// releasePrefetched waits for an unused prefetch to complete and releases the raw bytes kept for it
+func (t *Tracker) releasePrefetched(prefetched *prefetchedBlock) {
+	<-prefetched.done
+	if prefetched.block != nil {
+		t.takeRawBytes(prefetched.block)
+	}
+}
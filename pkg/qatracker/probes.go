@@ -0,0 +1,116 @@
+package qatracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// probesShutdownTimeout bounds how long the probes server waits for in-flight probes on shutdown
+const probesShutdownTimeout = 5 * time.Second
+
+// Probes serves the liveness and readiness probes of an orchestrator over HTTP. The tracker is
+// ready once a comparison completed, which takes a successful fetch from both sources, and stays
+// ready while comparisons keep completing within the staleness window.
+type Probes struct {
+	listenAddr string
+	staleness  time.Duration
+	server     *http.Server
+
+	// Unix nanoseconds of the last completed comparison, 0 before the first one
+	lastComparison atomic.Int64
+}
+
+// probeStatus is the body of the readiness probe
+type probeStatus struct {
+	Ready          bool       `json:"ready"`
+	LastComparison *time.Time `json:"last_comparison,omitempty"`
+	Staleness      string     `json:"staleness"`
+}
+
+// NewProbes creates the probes served on listenAddr, e.g. ":8080", once started. /healthz
+// answers as long as the process is alive, /readyz only while the last comparison is at most
+// staleness old.
+func NewProbes(listenAddr string, staleness time.Duration) *Probes {
+	p := &Probes{listenAddr: listenAddr, staleness: staleness}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", p.serveReadiness)
+	p.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	return p
+}
+
+// Start listens on the probes address and serves the probes in the background, a listen error
+// is returned immediately
+func (p *Probes) Start(logger *zap.Logger) error {
+	listener, err := net.Listen("tcp", p.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for health probes on %s: %w", p.listenAddr, err)
+	}
+
+	logger.Info("Serving health probes", zap.String("listen_addr", listener.Addr().String()), zap.Duration("staleness", p.staleness))
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Health probes server failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the probes server, letting in-flight probes complete
+func (p *Probes) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), probesShutdownTimeout)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
+
+// Record marks a comparison as completed at the time of its result. A skipped slot doesn't
+// count, one of the sources may not have been fetched.
+func (p *Probes) Record(result Result) {
+	if result.Skipped {
+		return
+	}
+	p.lastComparison.Store(result.Time.UnixNano())
+}
+
+// status returns the readiness at now
+func (p *Probes) status(now time.Time) probeStatus {
+	status := probeStatus{Staleness: p.staleness.String()}
+
+	if last := p.lastComparison.Load(); last != 0 {
+		lastComparison := time.Unix(0, last)
+		status.LastComparison = &lastComparison
+		status.Ready = now.Sub(lastComparison) <= p.staleness
+	}
+	return status
+}
+
+// serveReadiness answers 200 when ready and 503 otherwise, with the readiness status as JSON
+func (p *Probes) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	status := p.status(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// WithProbes serves the health probes while the tracker runs
+func WithProbes(probes *Probes) Option {
+	return func(t *Tracker) {
+		t.probes = probes
+	}
+}
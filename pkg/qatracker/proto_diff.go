@@ -0,0 +1,112 @@
+package qatracker
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/google/go-cmp/cmp"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// unclassifiedCategory is the category of the field diffs located by the generic proto
+// comparison, outside of the fields diffBlocks knows about
+const unclassifiedCategory = "Unclassified"
+
+// maxProtoFieldDiffs caps the field diffs reported by the generic proto comparison, a shifted
+// list differs at every following index
+const maxProtoFieldDiffs = 100
+
+// protoFieldDiffs compares two blocks field by field through protocmp, so a difference in any
+// field is located, including fields added to the protobuf definitions after diffBlocks was
+// written. Transactions are identified by their Firehose signature like in diffBlocks.
+func protoFieldDiffs(a, b *pbsol.Block) []FieldDiff {
+	reporter := &protoDiffReporter{a: a, b: b}
+	cmp.Equal(a, b, protocmp.Transform(), cmp.Reporter(reporter))
+	return reporter.diffs
+}
+
+// protoDiffReporter is a cmp.Reporter recording a FieldDiff for every differing leaf value
+type protoDiffReporter struct {
+	a, b  *pbsol.Block
+	path  cmp.Path
+	diffs []FieldDiff
+}
+
+func (r *protoDiffReporter) PushStep(step cmp.PathStep) {
+	r.path = append(r.path, step)
+}
+
+func (r *protoDiffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+func (r *protoDiffReporter) Report(result cmp.Result) {
+	if result.Equal() || len(r.diffs) >= maxProtoFieldDiffs {
+		return
+	}
+
+	path, transaction := r.fieldPath()
+	firehose, rpcFetcher := r.path.Last().Values()
+	r.diffs = append(r.diffs, FieldDiff{
+		Category:    unclassifiedCategory,
+		Path:        path,
+		Transaction: transaction,
+		Firehose:    formatReflectDiffValue(firehose),
+		RPCFetcher:  formatReflectDiffValue(rpcFetcher),
+	})
+}
+
+// fieldPath renders the current path with the proto field names, e.g.
+// transactions[<signature>].meta.fee, and returns the signature of the transaction it is in
+func (r *protoDiffReporter) fieldPath() (string, string) {
+	var b strings.Builder
+	transaction := ""
+	for _, step := range r.path {
+		switch step := step.(type) {
+		case cmp.MapIndex:
+			if step.Key().Kind() == reflect.String {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(step.Key().String())
+			}
+		case cmp.SliceIndex:
+			ix, iy := step.SplitKeys()
+			if b.String() == "transactions" {
+				transaction = r.transactionSignature(ix, iy)
+				fmt.Fprintf(&b, "[%s]", transaction)
+				continue
+			}
+			index := ix
+			if index < 0 {
+				index = iy
+			}
+			fmt.Fprintf(&b, "[%d]", index)
+		}
+	}
+	return b.String(), transaction
+}
+
+// transactionSignature returns the base58 signature of the transaction at index ix of the
+// Firehose block, or at index iy of the other block when it only exists there
+func (r *protoDiffReporter) transactionSignature(ix, iy int) string {
+	if ix >= 0 && ix < len(r.a.Transactions) {
+		return solana.Base58(transactionSignature(r.a.Transactions[ix])).String()
+	}
+	if iy >= 0 && iy < len(r.b.Transactions) {
+		return solana.Base58(transactionSignature(r.b.Transactions[iy])).String()
+	}
+	return "?"
+}
+
+// formatReflectDiffValue renders a value reported by cmp, a value absent from one side is
+// reported as missing
+func formatReflectDiffValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "missing"
+	}
+	return formatDiffValue(value.Interface())
+}
@@ -0,0 +1,74 @@
+package qatracker
+
+import (
+	"go.uber.org/zap"
+)
+
+// quorumCategory is the diff category of a Firehose block disagreeing with the quorum of RPC
+// providers while matching the primary endpoint, no field diff locates the difference then
+const quorumCategory = "Quorum"
+
+// WithRPCQuorum makes the agreement of at least quorum RPC providers, the primary endpoint
+// included, define the expected block: a comparison matches when Firehose agrees with the
+// quorum, whatever the primary endpoint returned, so a single bad RPC node doesn't raise alerts.
+// When no quorum is reached, e.g. providers are unavailable, the primary endpoint decides.
+func WithRPCQuorum(quorum int) Option {
+	return func(t *Tracker) {
+		t.rpcQuorum = quorum
+	}
+}
+
+// quorumOutcome reports whether Firehose agrees with the quorum of providers, with the checksum
+// the quorum agreed on otherwise. reached is false when no checksum got quorum votes.
+func quorumOutcome(providers []ProviderResult, quorum int) (match bool, checksum string, reached bool) {
+	agreeing := 0
+	votes := map[string]int{}
+	for _, provider := range providers {
+		switch {
+		case provider.Error != "":
+		case provider.Match:
+			agreeing++
+		default:
+			votes[provider.Checksum]++
+		}
+	}
+
+	if agreeing >= quorum {
+		return true, "", true
+	}
+	for checksum, count := range votes {
+		if count >= quorum {
+			return false, checksum, true
+		}
+	}
+	return false, "", false
+}
+
+// applyQuorum decides the outcome of result by the quorum of its providers. It returns the diff
+// reported when Firehose disagrees with the quorum but matches the primary endpoint, which has
+// no field diffs.
+func (t *Tracker) applyQuorum(result *Result) []FieldDiff {
+	if t.rpcQuorum == 0 {
+		return nil
+	}
+
+	match, checksum, reached := quorumOutcome(result.Providers, t.rpcQuorum)
+	if !reached {
+		t.logger.Warn("No quorum of RPC providers, the primary endpoint decides",
+			zap.Uint64("slot", result.Slot),
+			zap.Int("quorum", t.rpcQuorum))
+		return nil
+	}
+
+	primaryMatch := result.Match
+	result.Match = match
+	switch {
+	case match && !primaryMatch:
+		t.logger.Warn("Firehose agrees with the quorum of RPC providers, ignoring the primary endpoint mismatch",
+			zap.Uint64("slot", result.Slot),
+			zap.String("primary_checksum", result.RPCFetcherChecksum))
+	case !match && primaryMatch:
+		return []FieldDiff{{Category: quorumCategory, Path: "checksum", Firehose: result.FirehoseChecksum, RPCFetcher: checksum}}
+	}
+	return nil
+}
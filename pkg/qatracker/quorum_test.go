@@ -0,0 +1,100 @@
+package qatracker
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestQuorumOutcome(t *testing.T) {
+	agree := ProviderResult{Checksum: "firehose", Match: true}
+	minority := ProviderResult{Checksum: "minority"}
+	majority := ProviderResult{Checksum: "majority"}
+	failed := ProviderResult{Error: "timeout"}
+
+	tests := []struct {
+		name         string
+		providers    []ProviderResult
+		quorum       int
+		wantMatch    bool
+		wantChecksum string
+		wantReached  bool
+	}{
+		{name: "all agree with firehose", providers: []ProviderResult{agree, agree, agree}, quorum: 2, wantMatch: true, wantReached: true},
+		{name: "2 of 3 agree with firehose", providers: []ProviderResult{majority, agree, agree}, quorum: 2, wantMatch: true, wantReached: true},
+		{name: "2 of 3 disagree with firehose on the same block", providers: []ProviderResult{majority, majority, agree}, quorum: 2, wantChecksum: "majority", wantReached: true},
+		{name: "firehose matches the minority", providers: []ProviderResult{agree, majority, majority}, quorum: 2, wantChecksum: "majority", wantReached: true},
+		{name: "disagreeing providers split", providers: []ProviderResult{minority, majority, agree}, quorum: 2},
+		{name: "tie without quorum", providers: []ProviderResult{agree, agree, majority, majority}, quorum: 3},
+		{name: "errored providers count neither way", providers: []ProviderResult{agree, failed, failed}, quorum: 2},
+		{name: "errored provider among a quorum", providers: []ProviderResult{failed, agree, agree}, quorum: 2, wantMatch: true, wantReached: true},
+		{name: "no providers", quorum: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, checksum, reached := quorumOutcome(test.providers, test.quorum)
+			if match != test.wantMatch || checksum != test.wantChecksum || reached != test.wantReached {
+				t.Fatalf("expected (%t, %q, %t), got (%t, %q, %t)", test.wantMatch, test.wantChecksum, test.wantReached, match, checksum, reached)
+			}
+		})
+	}
+}
+
+func TestApplyQuorum(t *testing.T) {
+	agree := ProviderResult{Checksum: "firehose", Match: true}
+	other := ProviderResult{Checksum: "other"}
+
+	tests := []struct {
+		name      string
+		quorum    int
+		result    Result
+		wantMatch bool
+		wantDiffs []FieldDiff
+	}{
+		{
+			name:      "disabled keeps the primary outcome",
+			result:    Result{Match: false, Providers: []ProviderResult{other, agree, agree}},
+			wantMatch: false,
+		},
+		{
+			name:      "quorum overrides a primary mismatch",
+			quorum:    2,
+			result:    Result{Match: false, Providers: []ProviderResult{other, agree, agree}},
+			wantMatch: true,
+		},
+		{
+			name:      "quorum overrides a primary match",
+			quorum:    2,
+			result:    Result{Match: true, FirehoseChecksum: "firehose", Providers: []ProviderResult{agree, other, other}},
+			wantMatch: false,
+			wantDiffs: []FieldDiff{{Category: quorumCategory, Path: "checksum", Firehose: "firehose", RPCFetcher: "other"}},
+		},
+		{
+			name:      "quorum confirms a primary mismatch without a quorum diff",
+			quorum:    2,
+			result:    Result{Match: false, Providers: []ProviderResult{other, other, agree}},
+			wantMatch: false,
+		},
+		{
+			name:      "no quorum keeps the primary outcome",
+			quorum:    2,
+			result:    Result{Match: true, Providers: []ProviderResult{agree, other, {Error: "timeout"}}},
+			wantMatch: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := &Tracker{logger: zap.NewNop(), rpcQuorum: test.quorum}
+			result := test.result
+
+			diffs := tracker.applyQuorum(&result)
+			if result.Match != test.wantMatch {
+				t.Fatalf("expected match %t, got %t", test.wantMatch, result.Match)
+			}
+			if !reflect.DeepEqual(diffs, test.wantDiffs) {
+				t.Fatalf("unexpected diffs\n got: %+v\nwant: %+v", diffs, test.wantDiffs)
+			}
+		})
+	}
+}
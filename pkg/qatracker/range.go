@@ -0,0 +1,265 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+)
+
+// RangeSummary aggregates the results of a range comparison
+type RangeSummary struct {
+	Compared   int
+	Mismatches int
+	Errors     int
+	Skipped    int
+	// AlreadyClean is the number of slots skipped because the compared-slot state records them clean
+	AlreadyClean    int
+	MismatchedSlots []uint64
+	Categories      map[string]int
+	Elapsed         time.Duration
+
+	// Guards the counts updated by the comparison workers
+	mu sync.Mutex
+}
+
+// Matched returns the number of compared slots whose blocks matched
+func (s *RangeSummary) Matched() int {
+	return s.Compared - s.Mismatches
+}
+
+// BlocksPerSecond returns the comparison throughput of the range
+func (s *RangeSummary) BlocksPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Compared) / s.Elapsed.Seconds()
+}
+
+// logRangeThroughput logs the comparison throughput of a range, with the share of RPC blocks
+// served by JSON-RPC batches when batching is enabled
+func (t *Tracker) logRangeThroughput(summary *RangeSummary) {
+	fields := []zap.Field{
+		zap.Int("compared", summary.Compared),
+		zap.Duration("elapsed", summary.Elapsed),
+		zap.Float64("blocks_per_second", summary.BlocksPerSecond()),
+	}
+	if t.rpcBatcher != nil {
+		batches, served := t.rpcBatcher.stats()
+		fields = append(fields, zap.Int("rpc_batches", batches), zap.Int("rpc_blocks_from_batches", served))
+	}
+	t.logger.Info("Range comparison throughput", fields...)
+}
+
+func (s *RangeSummary) add(result *Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Compared++
+	if result.Match {
+		return
+	}
+
+	s.Mismatches++
+	s.MismatchedSlots = append(s.MismatchedSlots, result.Slot)
+	for _, category := range result.DiffCategories {
+		s.Categories[category]++
+	}
+}
+
+func (s *RangeSummary) addError() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Errors++
+}
+
+func (s *RangeSummary) addSkip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Skipped++
+}
+
+func (s *RangeSummary) addAlreadyClean(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AlreadyClean += count
+}
+
+// addSkipped classifies the slots in [from, to) missing from the Firehose stream, i.e. skipped
+// by their leader, according to the skipped slot policy
+func (t *Tracker) addSkipped(s *RangeSummary, from, to uint64) {
+	for slot := from; slot < to; slot++ {
+		if result := t.classifySkippedSlot(slot, "firehose"); result != nil {
+			s.add(result)
+		} else {
+			s.addSkip()
+			t.recordCleanSlot(slot)
+		}
+	}
+}
+
+// PrintFieldsReport writes the mismatch tally per diff category, most frequent first
+func (s *RangeSummary) PrintFieldsReport(w io.Writer) {
+	fmt.Fprintf(w, "Diff categories over %d mismatches:\n", s.Mismatches)
+
+	categories := TopCategories(s.Categories, 0)
+	if len(categories) == 0 {
+		fmt.Fprintf(w, "  (none)\n")
+		return
+	}
+	for _, category := range categories {
+		fmt.Fprintf(w, "  %-24s %d\n", category, s.Categories[category])
+	}
+}
+
+// CompareRange streams every final block in [startSlot, stopSlot] from Firehose and compares
+// each of them with the RPC fetcher. Per-slot failures are counted and logged so a single
+// bad slot doesn't abort the whole range. With the compared-slot state enabled, the slots it
+// records clean are skipped and the stream starts after the leading clean ones.
+func (t *Tracker) CompareRange(ctx context.Context, startSlot, stopSlot uint64) (*RangeSummary, error) {
+	summary := &RangeSummary{Categories: map[string]int{}}
+	defer t.flushResults()
+	defer t.sendAlertSummary()
+
+	start := time.Now()
+	defer func() {
+		// Parallel workers complete out of order
+		slices.Sort(summary.MismatchedSlots)
+		summary.Elapsed = time.Since(start)
+		t.logRangeThroughput(summary)
+	}()
+
+	// Blocks compared one at a time share a single comparison slot covering the range, the
+	// workers of a pool take one each
+	pool := t.newComparisonPool()
+	if pool == nil {
+		if err := t.acquireComparison(ctx); err != nil {
+			return summary, err
+		}
+		defer t.releaseComparison()
+	}
+	defer pool.wait()
+
+	t.logger.Info("Comparing slot range", zap.Uint64("start_slot", startSlot), zap.Uint64("stop_slot", stopSlot))
+
+	// The stream is reopened from the slot following the last received block when it stalls
+	nextSlot := startSlot
+	if t.slotState != nil {
+		if err := t.loadSlotState(); err != nil {
+			return summary, err
+		}
+		if clean, ok := t.slotState.CleanRange(startSlot); ok {
+			nextSlot = min(clean.Stop, stopSlot) + 1
+			summary.addAlreadyClean(int(nextSlot - startSlot))
+			t.logger.Info("Skipping the leading slots already clean in the compared-slot state", zap.Uint64("next_slot", nextSlot))
+		}
+	}
+	stalls := 0
+	for nextSlot <= stopSlot {
+		err := t.compareRangeStream(ctx, nextSlot, stopSlot, summary, pool, func(slot uint64) {
+			nextSlot = slot + 1
+			stalls = 0
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errStreamStalled) {
+			return summary, err
+		}
+
+		stalls++
+		if stalls >= maxStreamEOFRetries {
+			return summary, fmt.Errorf("firehose stream stalled %d times in a row at slot %d: %w", stalls, nextSlot, err)
+		}
+		t.logger.Info("Reconnecting stalled Firehose stream", zap.Uint64("next_slot", nextSlot), zap.Int("attempt", stalls))
+	}
+
+	return summary, nil
+}
+
+// compareRangeStream opens a Firehose stream over [startSlot, stopSlot] and compares every
+// received block, on the workers of pool unless it is nil, calling progress with the slot of
+// each block received
+func (t *Tracker) compareRangeStream(ctx context.Context, startSlot, stopSlot uint64, summary *RangeSummary, pool *comparisonPool, progress func(slot uint64)) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer t.discardPrefetched()
+
+	req := &pbfirehose.Request{
+		StartBlockNum:   int64(startSlot),
+		StopBlockNum:    stopSlot,
+		FinalBlocksOnly: true,
+	}
+
+	_, firehoseClient := t.firehoseConnection()
+	stream, err := firehoseClient.Blocks(streamCtx, req, t.firehoseCallOptions()...)
+	if err != nil {
+		return classifyFirehoseError(fmt.Errorf("failed to create stream: %w", err))
+	}
+
+	// Slots missing from the stream between two received blocks were skipped by their leader
+	expectedSlot := startSlot
+	for {
+		resp, err := t.recvWithWatchdog(stream, cancel)
+		if errors.Is(err, io.EOF) {
+			t.addSkipped(summary, expectedSlot, stopSlot+1)
+			return nil
+		}
+		if err != nil {
+			return classifyFirehoseError(fmt.Errorf("failed to receive block: %w", err))
+		}
+
+		firehoseBlock, firehoseBlockSum, err := t.decodeFirehoseResponse(resp)
+		if err != nil {
+			summary.addError()
+			t.logger.Error("Error decoding Firehose block", zap.String("error_kind", errorKind(err)), zap.Error(err))
+			continue
+		}
+		progress(firehoseBlock.Slot)
+		t.addSkipped(summary, expectedSlot, firehoseBlock.Slot)
+		expectedSlot = firehoseBlock.Slot + 1
+		if t.slotClean(firehoseBlock.Slot) {
+			summary.addAlreadyClean(1)
+			continue
+		}
+		t.batchFetchRPCBlocks(ctx, firehoseBlock.Slot, stopSlot)
+		t.prefetchRPCBlocks(ctx, firehoseBlock.Slot+1, stopSlot)
+
+		err = pool.run(ctx, func() {
+			t.compareRangeBlock(ctx, firehoseBlock, firehoseBlockSum, summary)
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// compareRangeBlock compares a block of a range stream with the RPC fetcher and adds its result
+// to summary
+func (t *Tracker) compareRangeBlock(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string, summary *RangeSummary) {
+	// A skip of a backfilled slot is expected, it's classified by the skipped slot policy
+	result, err := t.compareWithRPCFetcher(ctx, firehoseBlock, firehoseBlockSum)
+	if errors.Is(err, ErrSkipped) {
+		if result := t.classifySkippedSlot(firehoseBlock.Slot, "rpc"); result != nil {
+			summary.add(result)
+		} else {
+			summary.addSkip()
+			t.recordCleanSlot(firehoseBlock.Slot)
+		}
+		return
+	}
+	if err != nil {
+		summary.addError()
+		t.logger.Error("Error comparing block", zap.Uint64("slot", firehoseBlock.Slot), zap.String("error_kind", errorKind(err)), zap.Error(err))
+		return
+	}
+
+	summary.add(result)
+}
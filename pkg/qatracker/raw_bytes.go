@@ -0,0 +1,61 @@
+package qatracker
+
+import (
+	"fmt"
+	"sync"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+)
+
+// rawBlockBytes holds the serialized bytes each source delivered, keyed by the decoded block,
+// until the comparison of that block completes
+type rawBlockBytes struct {
+	mu    sync.Mutex
+	bytes map[*pbsol.Block][]byte
+}
+
+// WithDumpRawBytes also writes the exact bytes each source delivered, before unmarshal, to
+// .bin files on mismatch so serialization differences can be told from semantic ones
+func WithDumpRawBytes() Option {
+	return func(t *Tracker) {
+		t.rawBlocks = &rawBlockBytes{bytes: map[*pbsol.Block][]byte{}}
+	}
+}
+
+// keepRawBytes retains the raw bytes block was decoded from, it's a no-op unless raw bytes are dumped
+func (t *Tracker) keepRawBytes(block *pbsol.Block, raw []byte) {
+	if t.rawBlocks == nil {
+		return
+	}
+
+	t.rawBlocks.mu.Lock()
+	defer t.rawBlocks.mu.Unlock()
+	t.rawBlocks.bytes[block] = raw
+}
+
+// takeRawBytes returns the raw bytes retained for block, if any, and releases them
+func (t *Tracker) takeRawBytes(block *pbsol.Block) []byte {
+	if t.rawBlocks == nil {
+		return nil
+	}
+
+	t.rawBlocks.mu.Lock()
+	defer t.rawBlocks.mu.Unlock()
+	raw := t.rawBlocks.bytes[block]
+	delete(t.rawBlocks.bytes, block)
+	return raw
+}
+
+// writeRawBytes writes the raw bytes taken for the block at slot to a .bin file named after name,
+// it returns "" when no raw bytes were retained (e.g. a block decoded a second time from the same bytes)
+func (t *Tracker) writeRawBytes(raw []byte, slot uint64, name string) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+
+	filename := t.artifactLocation(fmt.Sprintf("%s%s_%d.bin", t.outputPrefix, name, slot))
+	if err := t.writeArtifactFile(filename, raw); err != nil {
+		return "", fmt.Errorf("failed to write raw block bytes to file %s: %w", filename, err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,147 @@
+package qatracker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// firehoseReconnectInitialBackoff is the delay before the first Firehose reconnection, it
+	// doubles on every following attempt
+	firehoseReconnectInitialBackoff = time.Second
+	// firehoseReconnectMaxBackoff caps the delay between two Firehose reconnections
+	firehoseReconnectMaxBackoff = 30 * time.Second
+)
+
+// dialFirehose creates the gRPC connection to a Firehose endpoint
+func dialFirehose(endpoint string) (*grpc.ClientConn, error) {
+	// Setup connection options with TLS and increased message size limits for firehose
+	var dialOptions []grpc.DialOption
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	// Set max receive message size to 1GB to handle large Solana blocks
+	dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(1024*1024*1024)))
+	// Set max send message size to 1GB for completeness
+	dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(1024*1024*1024)))
+
+	return grpc.Dial(endpoint, dialOptions...)
+}
+
+// WithFirehoseReconnect rebuilds the Firehose connection up to attempts times, with exponential
+// backoff, when a fetch fails with a connection-level error. With refreshCredentials the
+// credentials are re-read from the environment on every reconnection, so a rotated
+// FIREHOSE_API_TOKEN is picked up without restarting the tracker.
+func WithFirehoseReconnect(attempts int, refreshCredentials bool) Option {
+	return func(t *Tracker) {
+		t.firehoseReconnectAttempts = attempts
+		t.refreshFirehoseCredentials = refreshCredentials
+	}
+}
+
+// isFirehoseConnectionError reports whether err means the Firehose connection itself is broken
+// or its credentials were rejected, as opposed to a failure of a single request
+func isFirehoseConnectionError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unauthenticated:
+		return true
+	}
+	return false
+}
+
+// firehoseConnection returns the current Firehose connection and its stream client
+func (t *Tracker) firehoseConnection() (*grpc.ClientConn, pbfirehose.StreamClient) {
+	t.firehoseMu.RLock()
+	defer t.firehoseMu.RUnlock()
+	return t.firehoseConn, t.firehoseClient
+}
+
+// receiveFirstBlockReconnecting receives the first block of a Firehose stream, rebuilding the
+// connection with exponential backoff when it fails with a connection-level error
+func (t *Tracker) receiveFirstBlockReconnecting(ctx context.Context, req *pbfirehose.Request) (*pbfirehose.Response, error) {
+	backoff := firehoseReconnectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		conn, _ := t.firehoseConnection()
+		resp, err := t.receiveFirstBlock(ctx, req, t.firehoseCallOptions())
+		if err == nil || !isFirehoseConnectionError(err) || attempt > t.firehoseReconnectAttempts {
+			return resp, err
+		}
+
+		t.logger.Warn("Firehose connection failed, reconnecting",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, firehoseReconnectMaxBackoff)
+
+		if err := t.reconnectFirehose(conn); err != nil {
+			t.logger.Warn("Failed to reconnect to Firehose", zap.Error(err))
+		}
+	}
+}
+
+// reconnectFirehose replaces the failed Firehose connection with a new one and refreshes the
+// credentials when enabled. Comparisons running concurrently may observe the same failure,
+// only the first one reconnects.
+func (t *Tracker) reconnectFirehose(failed *grpc.ClientConn) error {
+	t.firehoseMu.Lock()
+	defer t.firehoseMu.Unlock()
+
+	if t.firehoseConn != failed {
+		return nil
+	}
+
+	conn, err := dialFirehose(t.firehoseEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Firehose: %w", err)
+	}
+	if err := failed.Close(); err != nil {
+		t.logger.Debug("Failed to close previous Firehose connection", zap.Error(err))
+	}
+	t.firehoseConn = conn
+	t.firehoseClient = pbfirehose.NewStreamClient(conn)
+
+	if t.refreshFirehoseCredentials {
+		t.refreshFirehoseCredentialsFromEnv()
+	}
+	return nil
+}
+
+// refreshFirehoseCredentialsFromEnv re-reads the Firehose credentials from the environment, the
+// QA_* variables taking precedence over the unprefixed ones like on startup. Credentials missing
+// from the environment are kept. Callers must hold firehoseMu.
+func (t *Tracker) refreshFirehoseCredentialsFromEnv() {
+	token := firehoseCredentialFromEnv("firehose-api-token", "FIREHOSE_API_TOKEN")
+	apiKey := firehoseCredentialFromEnv("firehose-api-key", "FIREHOSE_API_KEY")
+	if token == "" && apiKey == "" {
+		return
+	}
+
+	if token != t.firehoseAPIToken || apiKey != t.firehoseAPIKey {
+		t.logger.Info("Refreshed Firehose credentials from the environment")
+	}
+	t.firehoseAPIToken = token
+	t.firehoseAPIKey = apiKey
+}
+
+// firehoseCredentialFromEnv returns the credential bound to flagName from its QA_* variable,
+// falling back to the legacy unprefixed variable
+func firehoseCredentialFromEnv(flagName, legacyVar string) string {
+	if value := os.Getenv(EnvVarName(flagName)); value != "" {
+		return value
+	}
+	return os.Getenv(legacyVar)
+}
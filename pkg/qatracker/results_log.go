@@ -0,0 +1,99 @@
+package qatracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ResultsLogEntry is one line of the results log
+type ResultsLogEntry struct {
+	Slot               uint64    `json:"slot"`
+	FirehoseChecksum   string    `json:"firehose_checksum"`
+	RPCFetcherChecksum string    `json:"rpc_fetcher_checksum"`
+	Match              bool      `json:"match"`
+	Skipped            bool      `json:"skipped,omitempty"`
+	DiffCategories     []string  `json:"diff_categories,omitempty"`
+	FirehoseFetchMs    int64     `json:"firehose_fetch_ms,omitempty"`
+	RPCFetcherFetchMs  int64     `json:"rpc_fetcher_fetch_ms,omitempty"`
+	Time               time.Time `json:"time"`
+}
+
+// ResultsLog appends every comparison result, matches included, as a JSON line to a file. Each
+// line is written with a single unbuffered write, so the file can be tailed while running.
+type ResultsLog struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenResultsLog opens the results log at path for appending, creating it when missing
+func OpenResultsLog(path string) (*ResultsLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create results log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results log %s: %w", path, err)
+	}
+
+	return &ResultsLog{path: path, file: file}, nil
+}
+
+// WithResultsLog appends every comparison result to the results log
+func WithResultsLog(log *ResultsLog) Option {
+	return func(t *Tracker) {
+		t.resultsLog = log
+	}
+}
+
+// Append writes the line of a comparison result
+func (l *ResultsLog) Append(result Result) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := ResultsLogEntry{
+		Slot:               result.Slot,
+		FirehoseChecksum:   result.FirehoseChecksum,
+		RPCFetcherChecksum: result.RPCFetcherChecksum,
+		Match:              result.Match,
+		Skipped:            result.Skipped,
+		DiffCategories:     result.DiffCategories,
+		FirehoseFetchMs:    result.FirehoseFetchMs,
+		RPCFetcherFetchMs:  result.RPCFetcherFetchMs,
+		Time:               result.Time,
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode results log entry: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write results log %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Close closes the results log file
+func (l *ResultsLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// appendResultsLog appends a result to the results log when enabled, a failed write is logged
+func (t *Tracker) appendResultsLog(result Result) {
+	if t.resultsLog == nil {
+		return
+	}
+	if err := t.resultsLog.Append(result); err != nil {
+		t.logger.Warn("Failed to append to results log", zap.Error(err))
+	}
+}
@@ -0,0 +1,169 @@
+package qatracker
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ResultsStats summarizes the comparison results stored since a point in time
+type ResultsStats struct {
+	Since    time.Time
+	Compared int
+	Matched  int
+	Skipped  int
+	// Mean fetch durations over the results they were measured for, zero when none was
+	MeanFirehoseFetch   time.Duration
+	MeanRPCFetcherFetch time.Duration
+	// RecentMismatches are the most recent mismatches, most recent first
+	RecentMismatches []Result
+}
+
+// Mismatched returns the number of compared slots whose blocks differed
+func (s ResultsStats) Mismatched() int {
+	return s.Compared - s.Matched
+}
+
+// MatchRate returns the match rate in percent, 100 when nothing was compared
+func (s ResultsStats) MatchRate() float64 {
+	if s.Compared == 0 {
+		return 100
+	}
+	return float64(s.Matched) * 100 / float64(s.Compared)
+}
+
+// SQLiteResultsStats computes the stats of the results stored in the SQLite database at path
+// since since, keeping the recent most recent mismatches. Only the rows of environment are
+// considered when it isn't empty.
+func SQLiteResultsStats(ctx context.Context, path, environment string, since time.Time, recent int) (ResultsStats, error) {
+	stats := ResultsStats{Since: since}
+
+	// Opening a missing file would create an empty database
+	if _, err := os.Stat(path); err != nil {
+		return stats, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return stats, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	defer db.Close()
+
+	const filter = "compared_at >= ? AND (? = '' OR environment = ?)"
+	args := []any{since.UTC().Format(sqliteTimeFormat), environment, environment}
+
+	var firehoseFetchMs, rpcFetcherFetchMs sql.NullFloat64
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*), COALESCE(SUM(match), 0), COALESCE(SUM(skipped), 0), AVG(firehose_fetch_ms), AVG(rpc_fetcher_fetch_ms) FROM comparison_results WHERE "+filter, args...).
+		Scan(&stats.Compared, &stats.Matched, &stats.Skipped, &firehoseFetchMs, &rpcFetcherFetchMs)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query results stats from %s: %w", path, err)
+	}
+	stats.MeanFirehoseFetch = time.Duration(firehoseFetchMs.Float64 * float64(time.Millisecond))
+	stats.MeanRPCFetcherFetch = time.Duration(rpcFetcherFetchMs.Float64 * float64(time.Millisecond))
+
+	rows, err := db.QueryContext(ctx, "SELECT slot, firehose_checksum, rpc_fetcher_checksum, skipped, diff_categories, firehose_file, rpc_fetcher_file, compared_at FROM comparison_results WHERE NOT match AND "+filter+" ORDER BY compared_at DESC, id DESC LIMIT ?", append(args, recent)...)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query recent mismatches from %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result Result
+		var slot int64
+		var categories, comparedAt string
+		if err := rows.Scan(&slot, &result.FirehoseChecksum, &result.RPCFetcherChecksum, &result.Skipped, &categories, &result.FirehoseFile, &result.RPCFetcherFile, &comparedAt); err != nil {
+			return stats, fmt.Errorf("failed to read recent mismatch from %s: %w", path, err)
+		}
+		result.Slot = uint64(slot)
+		if categories != "" {
+			result.DiffCategories = strings.Split(categories, ",")
+		}
+		if result.Time, err = time.Parse(sqliteTimeFormat, comparedAt); err != nil {
+			return stats, fmt.Errorf("invalid compared_at %q in %s: %w", comparedAt, path, err)
+		}
+		stats.RecentMismatches = append(stats.RecentMismatches, result)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("failed to read recent mismatches from %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// ResultsLogStats computes the stats of the results appended to the results log at path since
+// since, keeping the recent most recent mismatches
+func ResultsLogStats(path string, since time.Time, recent int) (ResultsStats, error) {
+	stats := ResultsStats{Since: since}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("failed to open results log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var firehoseFetches, rpcFetcherFetches []int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry ResultsLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return stats, fmt.Errorf("invalid results log entry at %s:%d: %w", path, line, err)
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+
+		stats.Compared++
+		if entry.Match {
+			stats.Matched++
+		}
+		if entry.Skipped {
+			stats.Skipped++
+		}
+		if entry.FirehoseFetchMs > 0 {
+			firehoseFetches = append(firehoseFetches, entry.FirehoseFetchMs)
+		}
+		if entry.RPCFetcherFetchMs > 0 {
+			rpcFetcherFetches = append(rpcFetcherFetches, entry.RPCFetcherFetchMs)
+		}
+
+		// The log is chronological, the last mismatches read are the most recent
+		if !entry.Match && recent > 0 {
+			if len(stats.RecentMismatches) == recent {
+				stats.RecentMismatches = stats.RecentMismatches[1:]
+			}
+			stats.RecentMismatches = append(stats.RecentMismatches, Result{
+				Slot:               entry.Slot,
+				FirehoseChecksum:   entry.FirehoseChecksum,
+				RPCFetcherChecksum: entry.RPCFetcherChecksum,
+				DiffCategories:     entry.DiffCategories,
+				Skipped:            entry.Skipped,
+				Time:               entry.Time,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("failed to read results log %s: %w", path, err)
+	}
+
+	slices.Reverse(stats.RecentMismatches)
+	stats.MeanFirehoseFetch = meanFetchDuration(firehoseFetches)
+	stats.MeanRPCFetcherFetch = meanFetchDuration(rpcFetcherFetches)
+	return stats, nil
+}
+
+// meanFetchDuration returns the mean of fetch durations in milliseconds, zero when empty
+func meanFetchDuration(durationsMs []int64) time.Duration {
+	if len(durationsMs) == 0 {
+		return 0
+	}
+	var total int64
+	for _, ms := range durationsMs {
+		total += ms
+	}
+	return time.Duration(total) * time.Millisecond / time.Duration(len(durationsMs))
+}
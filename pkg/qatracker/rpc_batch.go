@@ -0,0 +1,192 @@
+package qatracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"go.uber.org/zap"
+)
+
+// batchingRPCClient is the JSON-RPC client of the RPC fetcher in range mode. getBlock responses
+// fetched ahead in JSON-RPC batches are served from memory, every other call goes to the provider.
+// The batches reuse the getBlock parameters of the fetcher's first call so the fetcher decodes
+// exactly what it would have requested itself.
+type batchingRPCClient struct {
+	inner     jsonrpc.RPCClient
+	batchSize int
+	logger    *zap.Logger
+
+	mu sync.Mutex
+	// getBlock configuration object of the fetcher, nil until its first getBlock call
+	blockConfig any
+	responses   map[uint64]*jsonrpc.RPCResponse
+	// Set once the provider rejected a batch, requests are then individual
+	disabled bool
+	// Number of batch requests sent and of getBlock calls they served
+	batches int
+	served  int
+}
+
+func newBatchingRPCClient(endpoint string, batchSize int, logger *zap.Logger) *batchingRPCClient {
+	return &batchingRPCClient{
+		inner:     jsonrpc.NewClient(endpoint),
+		batchSize: batchSize,
+		logger:    logger,
+		responses: map[uint64]*jsonrpc.RPCResponse{},
+	}
+}
+
+// WithRPCBatchSize makes streamed comparisons (range and batch modes) fetch RPC blocks in
+// JSON-RPC batches of n slots, falling back to individual requests if the provider rejects them
+func WithRPCBatchSize(n int) Option {
+	return func(t *Tracker) {
+		t.rpcBatchSize = n
+	}
+}
+
+// getBlockSlot returns the slot of a getBlock call
+func getBlockSlot(method string, params []any) (uint64, bool) {
+	if method != "getBlock" || len(params) == 0 {
+		return 0, false
+	}
+	slot, ok := params[0].(uint64)
+	return slot, ok
+}
+
+func (c *batchingRPCClient) CallForInto(ctx context.Context, out any, method string, params []any) error {
+	if slot, ok := getBlockSlot(method, params); ok {
+		c.mu.Lock()
+		if c.blockConfig == nil && len(params) > 1 {
+			c.blockConfig = params[1]
+		}
+		response, found := c.responses[slot]
+		delete(c.responses, slot)
+		if found {
+			c.served++
+		}
+		c.mu.Unlock()
+
+		if found {
+			if response.Error != nil {
+				return response.Error
+			}
+			return json.Unmarshal(response.Result, out)
+		}
+	}
+
+	return c.inner.CallForInto(ctx, out, method, params)
+}
+
+func (c *batchingRPCClient) CallWithCallback(ctx context.Context, method string, params []any, callback func(*http.Request, *http.Response) error) error {
+	return c.inner.CallWithCallback(ctx, method, params, callback)
+}
+
+func (c *batchingRPCClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return c.inner.CallBatch(ctx, requests)
+}
+
+func (c *batchingRPCClient) Close() error {
+	return c.inner.Close()
+}
+
+// prefetch fetches the getBlock responses of [startSlot, stopSlot] (at most one batch) in a
+// single JSON-RPC batch request, unless startSlot is already in memory. Responses of slots before
+// startSlot were not used (the stream skipped them) and are dropped.
+func (c *batchingRPCClient) prefetch(ctx context.Context, startSlot, stopSlot uint64) {
+	c.mu.Lock()
+	for slot := range c.responses {
+		if slot < startSlot {
+			delete(c.responses, slot)
+		}
+	}
+	_, found := c.responses[startSlot]
+	blockConfig := c.blockConfig
+	if found || c.disabled || blockConfig == nil {
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	stopSlot = min(stopSlot, startSlot+uint64(c.batchSize)-1)
+	requests := make(jsonrpc.RPCRequests, 0, stopSlot-startSlot+1)
+	for slot := startSlot; slot <= stopSlot; slot++ {
+		requests = append(requests, &jsonrpc.RPCRequest{
+			JSONRPC: "2.0",
+			ID:      int(slot - startSlot),
+			Method:  "getBlock",
+			Params:  []any{slot, blockConfig},
+		})
+	}
+
+	responses, err := c.inner.CallBatch(ctx, requests)
+	if err == nil && len(responses) != len(requests) {
+		err = fmt.Errorf("provider answered %d of %d batched requests", len(responses), len(requests))
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.disabled = true
+		c.mu.Unlock()
+		c.logger.Warn("RPC provider rejected a JSON-RPC batch, falling back to individual requests", zap.Error(err))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches++
+	for _, response := range responses {
+		id, ok := responseID(response.ID)
+		if !ok || id < 0 || id >= int64(len(requests)) {
+			continue
+		}
+		c.responses[startSlot+uint64(id)] = response
+	}
+}
+
+// responseID returns the numeric ID of a batched response, depending on the decoder it is a
+// json.Number, a float64 or an int
+func responseID(id any) (int64, bool) {
+	switch id := id.(type) {
+	case json.Number:
+		value, err := id.Int64()
+		return value, err == nil
+	case float64:
+		return int64(id), true
+	case int:
+		return int64(id), true
+	case int64:
+		return id, true
+	default:
+		return 0, false
+	}
+}
+
+// stats returns the number of batch requests sent and of getBlock calls they served
+func (c *batchingRPCClient) stats() (batches, served int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.batches, c.served
+}
+
+// batchFetchRPCBlocks fetches, when RPC batching is enabled, the RPC blocks of the next batch of
+// slots starting at slot in one JSON-RPC batch request
+func (t *Tracker) batchFetchRPCBlocks(ctx context.Context, slot, stopSlot uint64) {
+	if t.rpcBatcher == nil {
+		return
+	}
+	t.rpcBatcher.prefetch(ctx, slot, stopSlot)
+}
+
+// newRPCClient creates the Solana RPC client, batching getBlock calls when enabled
+func (t *Tracker) newRPCClient() *rpc.Client {
+	if t.rpcBatchSize <= 1 {
+		return rpc.New(t.solanaRPCEndpoint)
+	}
+
+	t.rpcBatcher = newBatchingRPCClient(t.solanaRPCEndpoint, t.rpcBatchSize, t.logger)
+	return rpc.NewWithCustomRPCClient(t.rpcBatcher)
+}
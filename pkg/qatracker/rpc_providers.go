@@ -0,0 +1,204 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// rpcProvider is an additional Solana RPC endpoint the Firehose block is compared with
+type rpcProvider struct {
+	name   string
+	client *rpc.Client
+}
+
+// ProviderResult is the outcome of comparing the Firehose block with the block of one RPC
+// provider. A provider whose fetch failed neither agrees nor disagrees.
+type ProviderResult struct {
+	// Provider is the host of the endpoint, paths and query strings often hold API keys
+	Provider string `json:"provider"`
+	Checksum string `json:"checksum,omitempty"`
+	Match    bool   `json:"match"`
+	Error    string `json:"error,omitempty"`
+}
+
+// WithRPCProviders compares the Firehose block with the block of every endpoint, in addition to
+// the primary RPC endpoint, so a mismatch can be attributed to Firehose when no provider agrees
+// with it, or to a single flaky RPC node when others do. Artifacts and diffs are only produced
+// against the primary endpoint.
+func WithRPCProviders(endpoints []string) Option {
+	return func(t *Tracker) {
+		for _, endpoint := range endpoints {
+			t.rpcProviders = append(t.rpcProviders, rpcProvider{name: providerName(endpoint), client: rpc.New(endpoint)})
+		}
+	}
+}
+
+// providerName returns the host of an RPC endpoint, or the endpoint when it doesn't parse
+func providerName(endpoint string) string {
+	if parsed, err := url.Parse(endpoint); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return endpoint
+}
+
+// compareProviders fetches the slot of the Firehose block from every additional provider
+// concurrently and compares their checksums with the Firehose one. The primary endpoint comes
+// first, with the block already fetched; its outcome is the comparison result. Returns nil when
+// no additional provider is configured.
+func (t *Tracker) compareProviders(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum, rpcFetcherBlockSum string) []ProviderResult {
+	if len(t.rpcProviders) == 0 {
+		return nil
+	}
+
+	results := make([]ProviderResult, len(t.rpcProviders)+1)
+	results[0] = ProviderResult{Provider: providerName(t.solanaRPCEndpoint), Checksum: rpcFetcherBlockSum}
+
+	var wg sync.WaitGroup
+	for i, provider := range t.rpcProviders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := ProviderResult{Provider: provider.name}
+			match, checksum, err := t.compareProvider(ctx, provider, firehoseBlock, firehoseBlockSum)
+			if err != nil {
+				t.logger.Warn("Failed to fetch block from RPC provider", zap.String("provider", provider.name), zap.Uint64("slot", firehoseBlock.Slot), zap.Error(err))
+				result.Error = err.Error()
+			} else {
+				result.Checksum, result.Match = checksum, match
+			}
+			results[i+1] = result
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// compareProvider fetches the slot of the Firehose block from provider and reports whether both
+// blocks match. The block is compared like the primary endpoint one: transaction ordering is
+// normalized, the block time tolerance applies and the first block of an epoch gets the stricter
+// epoch boundary comparison, so the quorum never overrides a mismatch the primary would report.
+func (t *Tracker) compareProvider(ctx context.Context, provider rpcProvider, firehoseBlock *pbsol.Block, firehoseBlockSum string) (bool, string, error) {
+	fetchCtx, cancel := withFetchTimeout(ctx, t.rpcFetchTimeout)
+	defer cancel()
+
+	rawBlock, skipped, err := t.fetchRPCBlockFrom(fetchCtx, provider.client, firehoseBlock.Slot)
+	if err != nil {
+		return false, "", err
+	}
+	if skipped {
+		return false, "", fmt.Errorf("%w: block %d was skipped", ErrSkipped, firehoseBlock.Slot)
+	}
+	block, err := decodeRPCFetcherBlock(rawBlock, firehoseBlock.Slot)
+	if err != nil {
+		return false, "", err
+	}
+	if t.filterProgram != nil {
+		filterBlockByProgram(block, t.filterProgram)
+	}
+
+	checksum, err := calculateScopedChecksum(block, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to calculate sanitized checksum: %w", err)
+	}
+
+	normalized := checksum != firehoseBlockSum && t.normalizeOrder && !sameTransactionOrder(firehoseBlock, block)
+	if normalized {
+		if firehoseBlockSum, err = calculateNormalizedChecksum(firehoseBlock, t.checksumScope, t.ignoredFields); err != nil {
+			return false, "", err
+		}
+		if checksum, err = calculateNormalizedChecksum(block, t.checksumScope, t.ignoredFields); err != nil {
+			return false, "", err
+		}
+	}
+
+	if t.blockTimeTolerance > 0 {
+		if checksum, err = t.applyBlockTimeTolerance(firehoseBlock, block, checksum, normalized); err != nil {
+			return false, "", err
+		}
+	}
+
+	match := checksum == firehoseBlockSum
+	if match && isEpochBoundary(firehoseBlock) && len(diffEpochBoundary(firehoseBlock, block)) > 0 {
+		t.logger.Warn("Epoch boundary mismatch with RPC provider, rewards of the first block of the epoch differ",
+			zap.String("provider", provider.name),
+			zap.Uint64("slot", firehoseBlock.Slot))
+		match = false
+	}
+	return match, checksum, nil
+}
+
+// logProviderDisagreements warns about the providers disagreeing with Firehose, also when the
+// primary endpoint matched
+func (t *Tracker) logProviderDisagreements(slot uint64, providers []ProviderResult) {
+	if _, disagreeing, _ := splitProviders(providers); len(disagreeing) > 0 {
+		t.logger.Warn("RPC providers disagree with Firehose",
+			zap.Uint64("slot", slot),
+			zap.Strings("disagreeing", disagreeing),
+			zap.String("attribution", ProviderAttribution(providers)))
+	}
+}
+
+// ProviderAttribution attributes a disagreement between Firehose and the RPC providers:
+// "firehose" when no provider that answered agrees with Firehose, which takes at least two,
+// "rpc" when some agree and others don't, an empty string otherwise
+func ProviderAttribution(providers []ProviderResult) string {
+	agreeing, disagreeing, _ := splitProviders(providers)
+	switch {
+	case len(disagreeing) == 0:
+		return ""
+	case len(agreeing) == 0 && len(disagreeing) >= 2:
+		return "firehose"
+	case len(agreeing) > 0:
+		return "rpc"
+	default:
+		return ""
+	}
+}
+
+// splitProviders returns the names of the providers agreeing with Firehose, disagreeing with
+// it, and unavailable
+func splitProviders(providers []ProviderResult) (agreeing, disagreeing, unavailable []string) {
+	for _, provider := range providers {
+		switch {
+		case provider.Error != "":
+			unavailable = append(unavailable, provider.Provider)
+		case provider.Match:
+			agreeing = append(agreeing, provider.Provider)
+		default:
+			disagreeing = append(disagreeing, provider.Provider)
+		}
+	}
+	return agreeing, disagreeing, unavailable
+}
+
+// providersLine returns the notification line summarizing which RPC providers agree with
+// Firehose, or an empty string when a single provider is compared
+func providersLine(providers []ProviderResult) string {
+	if len(providers) == 0 {
+		return ""
+	}
+
+	agreeing, disagreeing, unavailable := splitProviders(providers)
+	line := fmt.Sprintf("• RPC providers agreeing with Firehose: %d/%d", len(agreeing), len(agreeing)+len(disagreeing))
+	if len(disagreeing) > 0 {
+		line += ", disagreeing: " + strings.Join(disagreeing, ", ")
+	}
+	if len(unavailable) > 0 {
+		line += ", unavailable: " + strings.Join(unavailable, ", ")
+	}
+	switch ProviderAttribution(providers) {
+	case "firehose":
+		line += " (the Firehose block is suspect)"
+	case "rpc":
+		line += " (the disagreeing providers are suspect)"
+	}
+	return line + "\n"
+}
@@ -0,0 +1,31 @@
+package qatracker
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostRunSummary posts the outcome of a range or single comparison run to Slack, so a scheduled
+// job that passed can be told from one that never ran. It is sent in addition to the
+// per-mismatch alerts.
+func (t *Tracker) PostRunSummary(run string, compared, mismatches, errors int) {
+	matched := compared - mismatches
+
+	title := "✅ *Solana Block QA Run Passed* ✅"
+	if mismatches > 0 || errors > 0 {
+		title = "⚠️ *Solana Block QA Run Failed* ⚠️"
+	}
+
+	message := fmt.Sprintf("%s\n"+
+		"%s"+
+		"%s: %d/%d slots matched\n"+
+		"• Mismatches: %d\n"+
+		"• Errors: %d\n"+
+		"• Time: %s",
+		title, t.environmentLine(), run, matched, compared, mismatches, errors, time.Now().Format("2006-01-02 15:04:05"))
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send run summary Slack notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,167 @@
+package qatracker
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SanitizeMode selects how sanitized fields (log messages) are cleared
+type SanitizeMode string
+
+const (
+	// SanitizeModeNull sets sanitized fields to nil, they are absent from JSON artifacts
+	SanitizeModeNull SanitizeMode = "null"
+	// SanitizeModeEmpty emits sanitized fields unpopulated, JSON artifacts then show them as
+	// present but empty. Every other unset field of the block is emitted as well, protojson
+	// can't restrict unpopulated fields to some paths.
+	SanitizeModeEmpty SanitizeMode = "empty"
+)
+
+// ParseSanitizeMode validates and returns the sanitize mode for the given value
+func ParseSanitizeMode(value string) (SanitizeMode, error) {
+	switch mode := SanitizeMode(strings.ToLower(value)); mode {
+	case SanitizeModeNull, SanitizeModeEmpty:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sanitize mode %q (valid values: null, empty)", value)
+	}
+}
+
+// WithSanitizeMode sets how sanitized fields are cleared in mismatch artifacts
+func WithSanitizeMode(mode SanitizeMode) Option {
+	return func(t *Tracker) {
+		t.sanitizeMode = mode
+	}
+}
+
+// DefaultIgnoredFields are the field paths stripped before checksumming when --ignore-fields
+// isn't set, log messages differ between Firehose and RPC
+var DefaultIgnoredFields = []string{"transactions.meta.log_messages"}
+
+// IgnoredFields are the resolved field paths, rooted at the block, stripped from both blocks
+// before checksumming because they legitimately differ by source
+type IgnoredFields [][]protoreflect.FieldDescriptor
+
+// ParseIgnoredFields resolves field paths rooted at the block, e.g. rewards or
+// transactions.meta.compute_units_consumed, descending into every element of a repeated message
+// field. A path not starting with a block field is relative to the transaction meta, e.g.
+// logMessages or returnData.data. Path elements match the proto or JSON field name,
+// case-insensitively.
+func ParseIgnoredFields(paths []string) (IgnoredFields, error) {
+	blockDescriptor := (&pbsol.Block{}).ProtoReflect().Descriptor()
+	transactionDescriptor := (&pbsol.ConfirmedTransaction{}).ProtoReflect().Descriptor()
+	metaPrefix := []protoreflect.FieldDescriptor{blockDescriptor.Fields().ByName("transactions"), transactionDescriptor.Fields().ByName("meta")}
+
+	fields := IgnoredFields{}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		names := strings.Split(strings.ToLower(path), ".")
+		descriptor := blockDescriptor
+		var resolved []protoreflect.FieldDescriptor
+		if findField(blockDescriptor, names[0]) == nil {
+			// Relative to the transaction meta, with an optional meta. prefix
+			descriptor = metaPrefix[1].Message()
+			resolved = slices.Clone(metaPrefix)
+			if names[0] == "meta" {
+				names = names[1:]
+			}
+		}
+
+		for _, name := range names {
+			if descriptor == nil {
+				return nil, fmt.Errorf("invalid ignored field %q: %s can only be ignored as a whole", path, resolved[len(resolved)-1].Name())
+			}
+			field := findField(descriptor, name)
+			if field == nil {
+				return nil, fmt.Errorf("invalid ignored field %q: no field %q in %s", path, name, descriptor.Name())
+			}
+			if len(resolved) == 0 && (field.Name() == "slot" || field.Name() == "parent_slot") {
+				return nil, fmt.Errorf("invalid ignored field %q: the slots identify the compared block and can't be ignored", path)
+			}
+			resolved = append(resolved, field)
+			descriptor = field.Message()
+			if field.IsMap() {
+				// Maps can only be ignored as a whole, repeated messages are descended into
+				descriptor = nil
+			}
+		}
+		fields = append(fields, resolved)
+	}
+	return fields, nil
+}
+
+// ReadIgnoredFieldsFile reads the field paths listed in a sanitization rules file, one path per
+// line, blank lines and lines starting with # are skipped
+func ReadIgnoredFieldsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignored fields file %s: %w", path, err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	return paths, nil
+}
+
+// findField returns the field of descriptor whose proto or JSON name is name, ignoring case
+func findField(descriptor protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := descriptor.Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if strings.ToLower(string(field.Name())) == name || strings.ToLower(field.JSONName()) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// WithIgnoredFields sets the fields stripped before checksumming
+func WithIgnoredFields(fields IgnoredFields) Option {
+	return func(t *Tracker) {
+		t.ignoredFields = fields
+	}
+}
+
+// clear clears every ignored field of block
+func (f IgnoredFields) clear(block protoreflect.Message) {
+	for _, path := range f {
+		clearFieldPath(block, path)
+	}
+}
+
+// clearFieldPath clears the field at path in message, in every element of the repeated
+// messages along the way
+func clearFieldPath(message protoreflect.Message, path []protoreflect.FieldDescriptor) {
+	field := path[0]
+	if len(path) == 1 {
+		message.Clear(field)
+		return
+	}
+	if !message.Has(field) {
+		return
+	}
+
+	if field.IsList() {
+		list := message.Mutable(field).List()
+		for i := range list.Len() {
+			clearFieldPath(list.Get(i).Message(), path[1:])
+		}
+		return
+	}
+	clearFieldPath(message.Mutable(field).Message(), path[1:])
+}
@@ -0,0 +1,211 @@
+package qatracker
+
+import (
+	"strings"
+	"testing"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+)
+
+// sanitizeTestBlock returns a block with two transactions holding log messages, rewards and
+// inner instructions, and a transaction without meta
+func sanitizeTestBlock() *pbsol.Block {
+	meta := func(fee uint64, logs ...string) *pbsol.TransactionStatusMeta {
+		return &pbsol.TransactionStatusMeta{
+			Fee:               fee,
+			LogMessages:       logs,
+			Rewards:           []*pbsol.Reward{{Pubkey: "validator", Lamports: 5}},
+			InnerInstructions: []*pbsol.InnerInstructions{{Index: 1, Instructions: []*pbsol.InnerInstruction{{ProgramIdIndex: 2}}}},
+		}
+	}
+	return &pbsol.Block{
+		Slot:       42,
+		ParentSlot: 41,
+		Blockhash:  "hash",
+		Rewards:    []*pbsol.Reward{{Pubkey: "leader", Lamports: 10}},
+		Transactions: []*pbsol.ConfirmedTransaction{
+			{Meta: meta(5000, "Program log: first")},
+			{Meta: meta(7000, "Program log: second", "Program log: third")},
+			{},
+		},
+	}
+}
+
+func TestParseIgnoredFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		wantPath []string
+		wantErr  string
+	}{
+		{name: "nested repeated path", paths: []string{"transactions.meta.log_messages"}, wantPath: []string{"transactions", "meta", "log_messages"}},
+		{name: "json names", paths: []string{"Transactions.Meta.LogMessages"}, wantPath: []string{"transactions", "meta", "log_messages"}},
+		{name: "relative to the transaction meta", paths: []string{"logMessages"}, wantPath: []string{"transactions", "meta", "log_messages"}},
+		{name: "relative to the transaction meta with its prefix", paths: []string{"meta.return_data.data"}, wantPath: []string{"transactions", "meta", "return_data", "data"}},
+		{name: "block field", paths: []string{"rewards"}, wantPath: []string{"rewards"}},
+		{name: "blank paths skipped", paths: []string{" ", ""}},
+		{name: "unknown path", paths: []string{"signatures"}, wantErr: "no field \"signatures\" in TransactionStatusMeta"},
+		{name: "unknown nested field", paths: []string{"transactions.meta.logs"}, wantErr: "no field \"logs\" in TransactionStatusMeta"},
+		{name: "path below a scalar field", paths: []string{"transactions.meta.fee.amount"}, wantErr: "fee can only be ignored as a whole"},
+		{name: "slot", paths: []string{"slot"}, wantErr: "the slots identify the compared block"},
+		{name: "parent slot", paths: []string{"parentSlot"}, wantErr: "the slots identify the compared block"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := ParseIgnoredFields(test.paths)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.wantPath == nil {
+				if len(fields) != 0 {
+					t.Fatalf("expected no fields, got %v", fields)
+				}
+				return
+			}
+			if len(fields) != 1 {
+				t.Fatalf("expected 1 field, got %d", len(fields))
+			}
+			var path []string
+			for _, field := range fields[0] {
+				path = append(path, string(field.Name()))
+			}
+			if strings.Join(path, ".") != strings.Join(test.wantPath, ".") {
+				t.Fatalf("expected path %v, got %v", test.wantPath, path)
+			}
+		})
+	}
+}
+
+func TestSanitizeBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		check func(t *testing.T, block *pbsol.Block)
+	}{
+		{
+			name:  "log messages of every transaction",
+			paths: DefaultIgnoredFields,
+			check: func(t *testing.T, block *pbsol.Block) {
+				for i, transaction := range block.Transactions[:2] {
+					if transaction.Meta.LogMessages != nil {
+						t.Errorf("transaction %d: expected no log messages, got %v", i, transaction.Meta.LogMessages)
+					}
+					if transaction.Meta.Fee == 0 {
+						t.Errorf("transaction %d: expected the fee to be kept", i)
+					}
+				}
+				if block.Transactions[2].Meta != nil {
+					t.Errorf("expected the transaction without meta to stay without meta")
+				}
+			},
+		},
+		{
+			name:  "block rewards only",
+			paths: []string{"rewards"},
+			check: func(t *testing.T, block *pbsol.Block) {
+				if block.Rewards != nil {
+					t.Errorf("expected no block rewards, got %v", block.Rewards)
+				}
+				if len(block.Transactions[0].Meta.Rewards) != 1 {
+					t.Errorf("expected the transaction rewards to be kept")
+				}
+			},
+		},
+		{
+			name:  "field of nested repeated messages",
+			paths: []string{"transactions.meta.inner_instructions.index"},
+			check: func(t *testing.T, block *pbsol.Block) {
+				for i, transaction := range block.Transactions[:2] {
+					inner := transaction.Meta.InnerInstructions[0]
+					if inner.Index != 0 || len(inner.Instructions) != 1 {
+						t.Errorf("transaction %d: expected only the index cleared, got %v", i, inner)
+					}
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := ParseIgnoredFields(test.paths)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			block := sanitizeTestBlock()
+			sanitizeBlock(block, fields)
+			if block.Slot != 42 || block.ParentSlot != 41 || block.Blockhash != "hash" {
+				t.Fatalf("expected the block identity to be kept, got %v", block)
+			}
+			test.check(t, block)
+		})
+	}
+}
+
+func TestCalculateSanitizedChecksum(t *testing.T) {
+	fields, err := ParseIgnoredFields(DefaultIgnoredFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksum := func(block *pbsol.Block) string {
+		sum, err := calculateSanitizedChecksum(block, fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sum
+	}
+
+	differentLogs := sanitizeTestBlock()
+	differentLogs.Transactions[0].Meta.LogMessages = []string{"Program log: truncated"}
+	if checksum(sanitizeTestBlock()) != checksum(differentLogs) {
+		t.Fatalf("expected blocks differing only in log messages to have the same checksum")
+	}
+
+	differentFee := sanitizeTestBlock()
+	differentFee.Transactions[1].Meta.Fee = 1
+	if checksum(sanitizeTestBlock()) == checksum(differentFee) {
+		t.Fatalf("expected blocks differing in fees to have different checksums")
+	}
+}
+
+func TestSanitizeModeArtifacts(t *testing.T) {
+	fields, err := ParseIgnoredFields(DefaultIgnoredFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode        SanitizeMode
+		wantPresent bool
+	}{
+		{mode: SanitizeModeNull},
+		{mode: SanitizeModeEmpty, wantPresent: true},
+	}
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			block := sanitizeTestBlock()
+			sanitizeBlock(block, fields)
+
+			tracker := &Tracker{sanitizeMode: test.mode}
+			data, err := tracker.jsonMarshalOptions().Marshal(block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output := strings.Join(strings.Fields(string(data)), "")
+			if present := strings.Contains(output, `"logMessages":[]`); present != test.wantPresent {
+				t.Fatalf("expected emptied log messages present %t, got %s", test.wantPresent, data)
+			}
+			if strings.Contains(output, "Programlog") {
+				t.Fatalf("expected no log message, got %s", data)
+			}
+		})
+	}
+}
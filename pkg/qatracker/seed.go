@@ -0,0 +1,41 @@
+package qatracker
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// seededRand is the single source of randomness of the tracker (sampling, jitter), seeded so a
+// run can be replayed with the exact same random sequence. It is safe for concurrent use.
+type seededRand struct {
+	seed uint64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func newSeededRand(seed uint64) *seededRand {
+	return &seededRand{seed: seed, rand: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Float64 returns a pseudo-random number in [0.0, 1.0)
+func (r *seededRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+// Int64N returns a pseudo-random number in [0, n)
+func (r *seededRand) Int64N(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int64N(n)
+}
+
+// WithSeed seeds all randomness of the tracker, a seed is generated when none is given. Re-running
+// with the logged seed reproduces the exact sampling and jitter sequence.
+func WithSeed(seed uint64) Option {
+	return func(t *Tracker) {
+		t.rand = newSeededRand(seed)
+	}
+}
@@ -0,0 +1,101 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// SelfCheckSource selects which source a self-check fetches twice
+type SelfCheckSource string
+
+const (
+	// SelfCheckSourceFirehose fetches the slot twice from Firehose
+	SelfCheckSourceFirehose SelfCheckSource = "firehose"
+	// SelfCheckSourceRPC fetches the slot twice from the RPC fetcher
+	SelfCheckSourceRPC SelfCheckSource = "rpc"
+)
+
+// ParseSelfCheckSource validates and returns the self-check source for the given value
+func ParseSelfCheckSource(value string) (SelfCheckSource, error) {
+	switch source := SelfCheckSource(strings.ToLower(value)); source {
+	case SelfCheckSourceFirehose, SelfCheckSourceRPC:
+		return source, nil
+	default:
+		return "", fmt.Errorf("invalid self-check source %q (valid values: firehose, rpc)", value)
+	}
+}
+
+// fetchFrom fetches the block at slot from the given self-check source
+func (t *Tracker) fetchFrom(ctx context.Context, source SelfCheckSource, slot uint64) (*pbsol.Block, string, error) {
+	if source != SelfCheckSourceRPC {
+		return t.fetchFirehoseBlock(ctx, slot)
+	}
+
+	block, checksum, err := t.fetchBlockWithRPCFetcher(ctx, slot)
+	if err == nil && block.Slot != slot {
+		t.takeRawBytes(block)
+		return nil, "", fmt.Errorf("%w: requested=%d rpc=%d", ErrSlotMismatch, slot, block.Slot)
+	}
+	return block, checksum, err
+}
+
+// SelfCheck fetches the block at slot twice from source and returns an error when both fetches
+// differ. A self-inconsistency is a source bug on its own, it is reported as such rather than
+// as a cross-source mismatch.
+func (t *Tracker) SelfCheck(ctx context.Context, source SelfCheckSource, slot uint64) error {
+	first, firstSum, err := t.fetchFrom(ctx, source, slot)
+	if err != nil {
+		return fmt.Errorf("error on first fetch of slot %d from %s: %w", slot, source, err)
+	}
+	defer t.takeRawBytes(first)
+	second, secondSum, err := t.fetchFrom(ctx, source, slot)
+	if err != nil {
+		return fmt.Errorf("error on second fetch of slot %d from %s: %w", slot, source, err)
+	}
+	defer t.takeRawBytes(second)
+
+	t.logger.Info("Comparing checksums of both fetches",
+		zap.String("source", string(source)),
+		zap.Uint64("slot", slot),
+		zap.String("first_checksum", firstSum),
+		zap.String("second_checksum", secondSum))
+	if firstSum == secondSum {
+		return nil
+	}
+
+	categories := FieldDiffCategories(diffBlocks(first, second))
+	t.logger.Error("Source is not deterministic, both fetches of the same slot differ",
+		zap.String("source", string(source)),
+		zap.Uint64("slot", slot),
+		zap.Strings("diff_categories", categories))
+
+	if err := t.prepareOutput(); err != nil {
+		return err
+	}
+	firstFilename := t.artifactPath(string(source)+"_selfcheck_first", slot)
+	secondFilename := t.artifactPath(string(source)+"_selfcheck_second", slot)
+	if err := t.writeBlockArtifacts(first, second, firstFilename, secondFilename); err != nil {
+		return fmt.Errorf("error writing self-check artifact files: %w", err)
+	}
+
+	message := fmt.Sprintf("🛑 *Solana Block QA Self-Inconsistency* 🛑\n"+
+		"%s"+
+		"Two fetches of slot %d from %s differ, the source is not deterministic\n"+
+		"• First checksum: `%s`\n"+
+		"• Second checksum: `%s`\n"+
+		"• Diff categories: %s\n"+
+		"• Artifacts: `%s` `%s`\n"+
+		"• Time: %s",
+		t.environmentLine(), slot, source, firstSum, secondSum, strings.Join(categories, ", "), firstFilename, secondFilename, time.Now().Format("2006-01-02 15:04:05"))
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send self-inconsistency Slack notification", zap.Error(err))
+	}
+
+	return fmt.Errorf("%s is not deterministic at slot %d: checksums %s and %s differ (categories: %v), artifacts written to %s and %s",
+		source, slot, firstSum, secondSum, categories, firstFilename, secondFilename)
+}
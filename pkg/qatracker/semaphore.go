@@ -0,0 +1,39 @@
+package qatracker
+
+import (
+	"context"
+)
+
+// WithMaxConcurrentComparisons limits how many comparisons run simultaneously across all code
+// paths, bounding the number of (up to 1GB) block pairs held in memory at once
+func WithMaxConcurrentComparisons(n int) Option {
+	return func(t *Tracker) {
+		t.comparisonSlots = make(chan struct{}, max(n, 1))
+	}
+}
+
+// tryAcquireComparison takes a comparison slot without blocking, it reports false when all
+// slots are in use
+func (t *Tracker) tryAcquireComparison() bool {
+	select {
+	case t.comparisonSlots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// acquireComparison takes a comparison slot, waiting until one is free or ctx is done
+func (t *Tracker) acquireComparison(ctx context.Context) error {
+	select {
+	case t.comparisonSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseComparison frees a slot taken by tryAcquireComparison or acquireComparison
+func (t *Tracker) releaseComparison() {
+	<-t.comparisonSlots
+}
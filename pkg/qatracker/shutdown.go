@@ -0,0 +1,60 @@
+package qatracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithShutdownTimeout sets how long the tracker waits for in-flight work on shutdown
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.shutdownTimeout = timeout
+	}
+}
+
+// drain waits for the in-flight comparisons (if any) to complete, then flushes pending
+// notifications. If the shutdown timeout elapses first, the in-flight work is cancelled
+// and an error is returned so the process exits non-zero.
+func (t *Tracker) drain(cancel context.CancelFunc, inFlight *sync.WaitGroup) error {
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+
+		inFlight.Wait()
+		t.FlushNotifications()
+	}()
+
+	select {
+	case <-drained:
+		t.logger.Info("Shutdown drain completed")
+		return nil
+	case <-time.After(t.shutdownTimeout):
+		cancel()
+		return fmt.Errorf("shutdown timeout of %s elapsed before in-flight work completed", t.shutdownTimeout)
+	}
+}
+
+// FlushNotifications waits for queued artifacts to be written, sends notifications that are still
+// pending, such as a partial email digest or coalesced alerts, and writes results still buffered for the results
+// store and the latest stats checkpoint
+func (t *Tracker) FlushNotifications() {
+	t.waitArtifacts()
+	t.flushResults()
+	t.sendAlertSummary()
+
+	if t.emailNotifier != nil && t.emailNotifier.Pending() {
+		t.logger.Info("Sending final email digest")
+		if err := t.emailNotifier.SendDigest(); err != nil {
+			t.logger.Error("Failed to send final email digest", zap.Error(err))
+		}
+	}
+	if t.statsCheckpoint != nil {
+		if err := t.statsCheckpoint.Flush(); err != nil {
+			t.logger.Error("Failed to flush final stats checkpoint", zap.Error(err))
+		}
+	}
+}
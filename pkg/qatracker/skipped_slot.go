@@ -0,0 +1,121 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// SkippedSlotPolicy selects how a slot skipped by its leader is classified
+type SkippedSlotPolicy string
+
+const (
+	// SkippedSlotPolicyIgnore leaves skipped slots out of the comparison statistics
+	SkippedSlotPolicyIgnore SkippedSlotPolicy = "ignore"
+	// SkippedSlotPolicyMatch counts skipped slots as matches
+	SkippedSlotPolicyMatch SkippedSlotPolicy = "match"
+	// SkippedSlotPolicyMismatch counts skipped slots as mismatches and notifies them
+	SkippedSlotPolicyMismatch SkippedSlotPolicy = "mismatch"
+)
+
+// skippedSlotCategory is the diff category of a skipped slot classified as a mismatch
+const skippedSlotCategory = "SkippedSlot"
+
+// ParseSkippedSlotPolicy validates and returns the skipped slot policy for the given value
+func ParseSkippedSlotPolicy(value string) (SkippedSlotPolicy, error) {
+	switch policy := SkippedSlotPolicy(strings.ToLower(value)); policy {
+	case SkippedSlotPolicyIgnore, SkippedSlotPolicyMatch, SkippedSlotPolicyMismatch:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid skipped slot policy %q (valid values: ignore, match, mismatch)", value)
+	}
+}
+
+// WithSkippedSlotPolicy sets how slots skipped by one or both sources are classified
+func WithSkippedSlotPolicy(policy SkippedSlotPolicy) Option {
+	return func(t *Tracker) {
+		t.skippedSlotPolicy = policy
+	}
+}
+
+// classifySkippedSlot classifies a slot skipped by source ("firehose" or "rpc") according to
+// the skipped slot policy. It returns nil when skipped slots are ignored, otherwise the result
+// is published like any comparison and a mismatch is notified.
+func (t *Tracker) classifySkippedSlot(slot uint64, source string) *Result {
+	if t.skippedSlotPolicy == SkippedSlotPolicyIgnore || t.skippedSlotPolicy == "" {
+		t.logger.Debug("Ignoring skipped slot", zap.Uint64("slot", slot), zap.String("skipped_by", source))
+		return nil
+	}
+
+	result := Result{
+		Slot:       slot,
+		Match:      t.skippedSlotPolicy == SkippedSlotPolicyMatch,
+		OrderMatch: true,
+		Skipped:    true,
+		Time:       time.Now(),
+	}
+	if !result.Match {
+		result.DiffCategories = []string{skippedSlotCategory}
+		t.logger.Warn("Skipped slot classified as a mismatch", zap.Uint64("slot", slot), zap.String("skipped_by", source))
+
+		message := fmt.Sprintf("⏭️ *Solana Block QA Skipped Slot* ⏭️\n"+
+			"%s"+
+			"Slot %d was skipped by %s, counted as a mismatch by the skipped slot policy\n"+
+			"• Time: %s",
+			t.environmentLine(), slot, source, result.Time.Format("2006-01-02 15:04:05"))
+		if err := t.postSlackMessage(message); err != nil {
+			t.logger.Error("Failed to send skipped slot Slack notification", zap.Error(err))
+		}
+	}
+
+	t.publishResult(result)
+	return &result
+}
+
+// compareHeadBlock compares a block Firehose produced while tracking the head with the RPC
+// fetcher. Unlike a requested slot, an RPC skip of that slot is a genuine data inconsistency, it
+// is counted as a mismatch and alerted whatever the skipped slot policy.
+func (t *Tracker) compareHeadBlock(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string) (*Result, error) {
+	result, err := t.compareWithRPCFetcher(ctx, firehoseBlock, firehoseBlockSum)
+	if !errors.Is(err, ErrSkipped) {
+		return result, err
+	}
+	return t.reportRPCSkippedHeadBlock(firehoseBlock, firehoseBlockSum), nil
+}
+
+// reportRPCSkippedHeadBlock counts the RPC skip of a slot Firehose produced a block for as a
+// mismatch, alerting and publishing it
+func (t *Tracker) reportRPCSkippedHeadBlock(firehoseBlock *pbsol.Block, firehoseBlockSum string) *Result {
+	result := &Result{
+		Slot:             firehoseBlock.Slot,
+		FirehoseChecksum: firehoseBlockSum,
+		OrderMatch:       true,
+		Skipped:          true,
+		DiffCategories:   []string{skippedSlotCategory},
+		Time:             time.Now(),
+	}
+	t.logger.Warn("RPC reports a slot Firehose produced a block for as skipped, counted as a mismatch",
+		zap.Uint64("slot", firehoseBlock.Slot),
+		zap.String("firehose_block_hash", firehoseBlock.Blockhash))
+
+	if !t.notifyOnRecoveryOnly && t.admitAlert(firehoseBlock.Slot) {
+		message := fmt.Sprintf("🚨 *Solana Block QA Alert* 🚨\n"+
+			"%s"+
+			"Firehose produced a block at slot %d but the RPC node reports the slot as skipped\n"+
+			"• Firehose block hash: `%s`\n"+
+			"• Firehose checksum: `%s`\n"+
+			"• Time: %s",
+			t.environmentLine(), firehoseBlock.Slot, firehoseBlock.Blockhash, firehoseBlockSum, result.Time.Format("2006-01-02 15:04:05"))
+		if err := t.postSlackMessage(message); err != nil {
+			t.logger.Error("Failed to send RPC skipped slot Slack notification", zap.Error(err))
+		}
+	}
+
+	t.publishResult(*result)
+	return result
+}
@@ -0,0 +1,70 @@
+package qatracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MatchRateCounter is the matched/total counter pair behind the data-quality SLA, e.g.
+// "99.95% of sampled slots matched this month". It is persisted with the stats checkpoint.
+type MatchRateCounter struct {
+	Since   time.Time `json:"since"`
+	Matched int       `json:"matched"`
+	Total   int       `json:"total"`
+}
+
+// Record counts a comparison outcome
+func (c *MatchRateCounter) Record(match bool) {
+	c.Total++
+	if match {
+		c.Matched++
+	}
+}
+
+// Reset zeroes the counters and starts a new window at now
+func (c *MatchRateCounter) Reset(now time.Time) {
+	*c = MatchRateCounter{Since: now}
+}
+
+// Rate returns the match rate in percent, 100 when nothing was compared yet
+func (c MatchRateCounter) Rate() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Matched) * 100 / float64(c.Total)
+}
+
+// ReadStatsCheckpoint reads the stats checkpoint file at path
+func ReadStatsCheckpoint(path string) (ComparisonStats, error) {
+	var stats ComparisonStats
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read stats checkpoint %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("failed to decode stats checkpoint %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// ResetStatsMatchRate restarts the match rate window of the stats checkpoint file at path at now.
+// The file is rewritten from a generic map, so the reset keeps the fields this version doesn't
+// know about.
+func ResetStatsMatchRate(path string, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read stats checkpoint %s: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to decode stats checkpoint %s: %w", path, err)
+	}
+	raw["match_rate"] = MatchRateCounter{Since: now}
+
+	checkpoint := &StatsCheckpoint{path: path}
+	return checkpoint.write(raw)
+}
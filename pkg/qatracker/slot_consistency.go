@@ -0,0 +1,51 @@
+package qatracker
+
+import (
+	"fmt"
+	"time"
+
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"go.uber.org/zap"
+)
+
+// slotMismatchCategory is the alert category of two sources returning blocks of different
+// slots, or of the same slot with different parent slots
+const slotMismatchCategory = "slot_mismatch"
+
+// checkSlotConsistency returns an ErrSlotMismatch error when both blocks aren't of the same slot
+// and parent slot. Comparing their checksums would otherwise report a redirect, an off-by-one or
+// a parent confusion as a data divergence.
+func checkSlotConsistency(block, otherBlock *pbsol.Block, source, otherSource string) error {
+	if block.Slot != otherBlock.Slot {
+		return fmt.Errorf("%w: %s=%d %s=%d", ErrSlotMismatch, source, block.Slot, otherSource, otherBlock.Slot)
+	}
+	if block.ParentSlot != otherBlock.ParentSlot {
+		return fmt.Errorf("%w: parent slot of %d %s=%d %s=%d", ErrSlotMismatch, block.Slot, source, block.ParentSlot, otherSource, otherBlock.ParentSlot)
+	}
+	return nil
+}
+
+// reportSlotMismatch alerts on two sources returning inconsistent slots, separately from the
+// checksum divergences since the blocks weren't compared
+func (t *Tracker) reportSlotMismatch(block, otherBlock *pbsol.Block, source, otherSource string, err error) {
+	t.logger.Error("Sources returned blocks of inconsistent slots, not compared",
+		zap.String("category", slotMismatchCategory),
+		zap.Uint64(source+"_slot", block.Slot),
+		zap.Uint64(otherSource+"_slot", otherBlock.Slot),
+		zap.Uint64(source+"_parent_slot", block.ParentSlot),
+		zap.Uint64(otherSource+"_parent_slot", otherBlock.ParentSlot),
+		zap.Error(err))
+
+	message := fmt.Sprintf("🛑 *Solana Block QA Slot Mismatch* 🛑\n"+
+		"%s"+
+		"Sources returned blocks of inconsistent slots, they were not compared\n"+
+		"• Category: `%s`\n"+
+		"• %s slot: %d (parent %d)\n"+
+		"• %s slot: %d (parent %d)\n"+
+		"• Error: `%s`\n"+
+		"• Time: %s",
+		t.environmentLine(), slotMismatchCategory, source, block.Slot, block.ParentSlot, otherSource, otherBlock.Slot, otherBlock.ParentSlot, err, time.Now().Format("2006-01-02 15:04:05"))
+	if err := t.postSlackMessage(message); err != nil {
+		t.logger.Error("Failed to send slot mismatch Slack notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,123 @@
+package qatracker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTimeFormat is the format of the compared_at column, understood by the SQLite date and
+// time functions
+const sqliteTimeFormat = "2006-01-02 15:04:05.000"
+
+// sqliteMigration creates the results table on first open, it is safe to run on every start
+const sqliteMigration = `
+CREATE TABLE IF NOT EXISTS comparison_results (
+	id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+	environment          TEXT    NOT NULL DEFAULT '',
+	slot                 INTEGER NOT NULL,
+	firehose_checksum    TEXT    NOT NULL,
+	rpc_fetcher_checksum TEXT    NOT NULL,
+	match                INTEGER NOT NULL,
+	order_match          INTEGER NOT NULL,
+	skipped              INTEGER NOT NULL DEFAULT 0,
+	diff_categories      TEXT    NOT NULL DEFAULT '',
+	firehose_file        TEXT    NOT NULL DEFAULT '',
+	rpc_fetcher_file     TEXT    NOT NULL DEFAULT '',
+	firehose_fetch_ms    INTEGER,
+	rpc_fetcher_fetch_ms INTEGER,
+	compared_at          TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS comparison_results_slot_idx ON comparison_results (slot);
+CREATE INDEX IF NOT EXISTS comparison_results_compared_at_idx ON comparison_results (environment, compared_at);
+`
+
+// SQLiteSink writes comparison results to an embedded SQLite database file, a queryable
+// history kept across restarts without running a database server
+type SQLiteSink struct {
+	db          *sql.DB
+	environment string
+}
+
+// NewSQLiteSink opens the SQLite database at path, creating it and the results table if needed.
+// Every row is tagged with environment (the output prefix) like with PostgresSink.
+func NewSQLiteSink(ctx context.Context, path, environment string) (*SQLiteSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create SQLite database directory: %w", err)
+	}
+
+	// Readers such as the stats subcommand may query the file while the tracker writes to it
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	// SQLite allows a single writer, inserts are serialized on one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, sqliteMigration); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create results table in %s: %w", path, err)
+	}
+
+	return &SQLiteSink{db: db, environment: environment}, nil
+}
+
+// Insert writes all results in a single transaction
+func (s *SQLiteSink) Insert(ctx context.Context, results []Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin results transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO comparison_results (environment, slot, firehose_checksum, rpc_fetcher_checksum, match, order_match, skipped, diff_categories, firehose_file, rpc_fetcher_file, firehose_fetch_ms, rpc_fetcher_fetch_ms, compared_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare results insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, result := range results {
+		_, err := stmt.ExecContext(ctx,
+			s.environment,
+			int64(result.Slot),
+			result.FirehoseChecksum,
+			result.RPCFetcherChecksum,
+			result.Match,
+			result.OrderMatch,
+			result.Skipped,
+			strings.Join(result.DiffCategories, ","),
+			result.FirehoseFile,
+			result.RPCFetcherFile,
+			sqliteNullableMs(result.FirehoseFetchMs),
+			sqliteNullableMs(result.RPCFetcherFetchMs),
+			result.Time.UTC().Format(sqliteTimeFormat),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert result of slot %d: %w", result.Slot, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert %d comparison results: %w", len(results), err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
+
+// sqliteNullableMs stores an unmeasured fetch duration as NULL
+func sqliteNullableMs(ms int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: ms, Valid: ms > 0}
+}
@@ -0,0 +1,312 @@
+package qatracker
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxStateMismatchedSlots caps the number of mismatched slots kept in the state file, the most
+// recent ones are kept
+const maxStateMismatchedSlots = 10000
+
+// maxStateCleanRanges caps the number of clean slot ranges kept in the state file, the ranges of
+// the highest slots are kept
+const maxStateCleanRanges = 10000
+
+// SlotStateData is the compared-slot state persisted in the state file
+type SlotStateData struct {
+	RunStartedAt     time.Time `json:"run_started_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	LastComparedSlot uint64    `json:"last_compared_slot"`
+	MismatchedSlots  []uint64  `json:"mismatched_slots"`
+	// CleanSlots are the slots whose last comparison matched, as sorted disjoint ranges
+	CleanSlots []SlotRange `json:"clean_slots,omitempty"`
+	// Cursor is the Firehose cursor of the last block handled in follow mode
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// SlotRange is an inclusive range of slots
+type SlotRange struct {
+	Start uint64 `json:"start"`
+	Stop  uint64 `json:"stop"`
+}
+
+// SlotState records the last compared slot and the mismatched slots to a JSON file after every
+// comparison, so a restarted tracker knows which slots were already verified
+type SlotState struct {
+	path string
+
+	mu   sync.Mutex
+	data SlotStateData
+}
+
+// WithSlotState persists the compared-slot state
+func WithSlotState(state *SlotState) Option {
+	return func(t *Tracker) {
+		t.slotState = state
+	}
+}
+
+// NewSlotState creates a SlotState persisted to path, the previous state is read by Load
+func NewSlotState(path string) *SlotState {
+	return &SlotState{
+		path: path,
+		data: SlotStateData{RunStartedAt: time.Now(), MismatchedSlots: []uint64{}},
+	}
+}
+
+// Load reads the state of a previous run from the state file, a missing file is a first run
+func (s *SlotState) Load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	var previous SlotStateData
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return fmt.Errorf("failed to decode state file %s: %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.LastComparedSlot = previous.LastComparedSlot
+	s.data.Cursor = previous.Cursor
+	s.data.CleanSlots = previous.CleanSlots
+	if previous.MismatchedSlots != nil {
+		s.data.MismatchedSlots = previous.MismatchedSlots
+	}
+	return nil
+}
+
+// LastComparedSlot returns the highest slot compared so far, across runs
+func (s *SlotState) LastComparedSlot() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.LastComparedSlot
+}
+
+// CleanRange returns the range of clean slots holding slot, ok is false when slot isn't clean
+func (s *SlotState) CleanRange(slot uint64) (SlotRange, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := findSlotRange(s.data.CleanSlots, slot)
+	if !ok {
+		return SlotRange{}, false
+	}
+	return s.data.CleanSlots[i], true
+}
+
+// RecordClean marks slot as clean without a comparison, e.g. a slot skipped by its leader on
+// both sources, and writes the state file
+func (s *SlotState) RecordClean(slot uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addCleanSlot(slot)
+	s.data.UpdatedAt = time.Now()
+	return s.write()
+}
+
+// Cursor returns the Firehose cursor follow mode resumes from, empty when none was recorded
+func (s *SlotState) Cursor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.Cursor
+}
+
+// RecordCursor sets the Firehose cursor follow mode resumes from and writes the state file
+func (s *SlotState) RecordCursor(cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Cursor = cursor
+	s.data.UpdatedAt = time.Now()
+	return s.write()
+}
+
+// Record adds a comparison result to the state and writes the state file
+func (s *SlotState) Record(result Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Concurrent comparisons may complete out of order
+	s.data.LastComparedSlot = max(s.data.LastComparedSlot, result.Slot)
+	if result.Match {
+		s.addCleanSlot(result.Slot)
+	} else {
+		s.removeCleanSlot(result.Slot)
+	}
+	if !result.Match && !slices.Contains(s.data.MismatchedSlots, result.Slot) {
+		s.data.MismatchedSlots = append(s.data.MismatchedSlots, result.Slot)
+		if overflow := len(s.data.MismatchedSlots) - maxStateMismatchedSlots; overflow > 0 {
+			s.data.MismatchedSlots = s.data.MismatchedSlots[overflow:]
+		}
+	}
+	s.data.UpdatedAt = time.Now()
+
+	return s.write()
+}
+
+// addCleanSlot adds slot to the clean ranges, merging it with the adjacent ones. Callers must
+// hold mu.
+func (s *SlotState) addCleanSlot(slot uint64) {
+	ranges := s.data.CleanSlots
+	if _, ok := findSlotRange(ranges, slot); ok {
+		return
+	}
+
+	// i is the first range after slot
+	i, _ := slices.BinarySearchFunc(ranges, slot, func(r SlotRange, slot uint64) int { return cmp.Compare(r.Start, slot) })
+	joinPrevious := i > 0 && ranges[i-1].Stop+1 == slot
+	joinNext := i < len(ranges) && ranges[i].Start == slot+1
+	switch {
+	case joinPrevious && joinNext:
+		ranges[i-1].Stop = ranges[i].Stop
+		ranges = slices.Delete(ranges, i, i+1)
+	case joinPrevious:
+		ranges[i-1].Stop = slot
+	case joinNext:
+		ranges[i].Start = slot
+	default:
+		ranges = slices.Insert(ranges, i, SlotRange{Start: slot, Stop: slot})
+	}
+
+	if overflow := len(ranges) - maxStateCleanRanges; overflow > 0 {
+		ranges = ranges[overflow:]
+	}
+	s.data.CleanSlots = ranges
+}
+
+// removeCleanSlot removes slot from the clean ranges, splitting the range holding it. Callers
+// must hold mu.
+func (s *SlotState) removeCleanSlot(slot uint64) {
+	i, ok := findSlotRange(s.data.CleanSlots, slot)
+	if !ok {
+		return
+	}
+
+	r := s.data.CleanSlots[i]
+	switch {
+	case r.Start == r.Stop:
+		s.data.CleanSlots = slices.Delete(s.data.CleanSlots, i, i+1)
+	case slot == r.Start:
+		s.data.CleanSlots[i].Start++
+	case slot == r.Stop:
+		s.data.CleanSlots[i].Stop--
+	default:
+		s.data.CleanSlots[i].Stop = slot - 1
+		s.data.CleanSlots = slices.Insert(s.data.CleanSlots, i+1, SlotRange{Start: slot + 1, Stop: r.Stop})
+	}
+}
+
+// findSlotRange returns the index of the range of sorted disjoint ranges holding slot
+func findSlotRange(ranges []SlotRange, slot uint64) (int, bool) {
+	i, found := slices.BinarySearchFunc(ranges, slot, func(r SlotRange, slot uint64) int { return cmp.Compare(r.Start, slot) })
+	if found {
+		return i, true
+	}
+	if i > 0 && ranges[i-1].Stop >= slot {
+		return i - 1, true
+	}
+	return 0, false
+}
+
+// write encodes the state to the state file, going through a temporary file renamed into place
+// so a crash mid-write never leaves a corrupted state behind. Callers must hold mu.
+func (s *SlotState) write() error {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to move state file into place: %w", err)
+	}
+	return nil
+}
+
+// loadSlotState loads the state of a previous run. In batch mode, the compared slots high-water
+// mark resumes from it so slots already verified aren't compared again, in range mode the clean
+// slots are skipped.
+func (t *Tracker) loadSlotState() error {
+	if err := t.slotState.Load(); err != nil {
+		return err
+	}
+
+	lastSlot := t.slotState.LastComparedSlot()
+	t.logger.Info("Loaded compared-slot state",
+		zap.String("path", t.slotState.path),
+		zap.Uint64("last_compared_slot", lastSlot),
+		zap.Int("clean_ranges", len(t.slotState.data.CleanSlots)),
+		zap.Bool("has_cursor", t.slotState.Cursor() != ""))
+	if t.batchSize > 0 && lastSlot > 0 {
+		t.batchHighWater.Store(lastSlot)
+	}
+	return nil
+}
+
+// recordSlotState adds a result to the compared-slot state when enabled, a failed write is
+// logged and retried with the next result
+func (t *Tracker) recordSlotState(result Result) {
+	if t.slotState == nil {
+		return
+	}
+	if err := t.slotState.Record(result); err != nil {
+		t.logger.Warn("Failed to write compared-slot state", zap.Error(err))
+	}
+}
+
+// recordCursor persists the Firehose cursor of the last block handled in follow mode when the
+// state is enabled, a failed write is logged and retried with the next block
+func (t *Tracker) recordCursor(cursor string) {
+	if t.slotState == nil {
+		return
+	}
+	if err := t.slotState.RecordCursor(cursor); err != nil {
+		t.logger.Warn("Failed to write Firehose cursor to the compared-slot state", zap.Error(err))
+	}
+}
+
+// slotClean reports whether the compared-slot state, when enabled, records slot as clean
+func (t *Tracker) slotClean(slot uint64) bool {
+	if t.slotState == nil {
+		return false
+	}
+	_, ok := t.slotState.CleanRange(slot)
+	return ok
+}
+
+// recordCleanSlot marks slot as clean in the compared-slot state when enabled, a failed write is
+// logged and retried with the next result
+func (t *Tracker) recordCleanSlot(slot uint64) {
+	if t.slotState == nil {
+		return
+	}
+	if err := t.slotState.RecordClean(slot); err != nil {
+		t.logger.Warn("Failed to write compared-slot state", zap.Error(err))
+	}
+}
@@ -0,0 +1,212 @@
+package qatracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxStatsMismatchedSlots caps the number of mismatched slots kept in the stats checkpoint,
+// the most recent ones are kept
+const maxStatsMismatchedSlots = 1000
+
+// ComparisonStats are the aggregate comparison stats persisted in the checkpoint file
+type ComparisonStats struct {
+	Since           time.Time      `json:"since"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	Compared        int            `json:"compared"`
+	Mismatches      int            `json:"mismatches"`
+	Errors          int            `json:"errors"`
+	LastSlot        uint64         `json:"last_slot"`
+	HeadSlot        uint64         `json:"head_slot,omitempty"`
+	SlotsBehind     int64          `json:"slots_behind,omitempty"`
+	LastHeartbeat   time.Time      `json:"last_heartbeat,omitzero"`
+	LinkageBreaks   map[string]int `json:"linkage_breaks,omitempty"`
+	MismatchedSlots []uint64       `json:"mismatched_slots"`
+	Categories      map[string]int `json:"categories"`
+	// MatchRate is the SLA counter pair, it restarts every match rate window
+	MatchRate MatchRateCounter `json:"match_rate"`
+}
+
+// StatsCheckpoint accumulates comparison stats and periodically writes them to a JSON file so
+// they survive a crash and can be inspected while the process isn't running
+type StatsCheckpoint struct {
+	path            string
+	interval        time.Duration
+	matchRateWindow time.Duration
+
+	mu    sync.Mutex
+	stats ComparisonStats
+}
+
+// WithStatsCheckpoint enables the periodic stats checkpoint
+func WithStatsCheckpoint(checkpoint *StatsCheckpoint) Option {
+	return func(t *Tracker) {
+		t.statsCheckpoint = checkpoint
+	}
+}
+
+// NewStatsCheckpoint creates a StatsCheckpoint flushed to path every interval. With resume,
+// the stats of a previous checkpoint at path are loaded and accumulation continues from them.
+// The match rate counters restart every matchRateWindow, 0 never restarts them.
+func NewStatsCheckpoint(path string, interval, matchRateWindow time.Duration, resume bool) (*StatsCheckpoint, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("stats flush interval must be positive")
+	}
+
+	now := time.Now()
+	checkpoint := &StatsCheckpoint{
+		path:            path,
+		interval:        interval,
+		matchRateWindow: matchRateWindow,
+		stats: ComparisonStats{
+			Since:      now,
+			Categories: map[string]int{},
+			MatchRate:  MatchRateCounter{Since: now},
+		},
+	}
+
+	if resume {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// Nothing to resume from on the first run
+		case err != nil:
+			return nil, fmt.Errorf("failed to read stats checkpoint %s: %w", path, err)
+		default:
+			if err := json.Unmarshal(data, &checkpoint.stats); err != nil {
+				return nil, fmt.Errorf("failed to decode stats checkpoint %s: %w", path, err)
+			}
+			if checkpoint.stats.Categories == nil {
+				checkpoint.stats.Categories = map[string]int{}
+			}
+			// Checkpoints written before the match rate was tracked start a window now
+			if checkpoint.stats.MatchRate.Since.IsZero() {
+				checkpoint.stats.MatchRate.Since = now
+			}
+		}
+	}
+
+	return checkpoint, nil
+}
+
+// Record adds a comparison result to the stats
+func (c *StatsCheckpoint) Record(result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Compared++
+	c.stats.LastSlot = result.Slot
+
+	if c.matchRateWindow > 0 && time.Since(c.stats.MatchRate.Since) >= c.matchRateWindow {
+		c.stats.MatchRate.Reset(time.Now())
+	}
+	c.stats.MatchRate.Record(result.Match)
+
+	if result.Match {
+		return
+	}
+
+	c.stats.Mismatches++
+	c.stats.MismatchedSlots = append(c.stats.MismatchedSlots, result.Slot)
+	if extra := len(c.stats.MismatchedSlots) - maxStatsMismatchedSlots; extra > 0 {
+		c.stats.MismatchedSlots = c.stats.MismatchedSlots[extra:]
+	}
+	for _, category := range result.DiffCategories {
+		c.stats.Categories[category]++
+	}
+}
+
+// RecordHead records the latest observed Firehose head slot and its estimated lag behind real time
+func (c *StatsCheckpoint) RecordHead(slot uint64, slotsBehind int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.HeadSlot = slot
+	c.stats.SlotsBehind = slotsBehind
+}
+
+// RecordHeartbeat records the time of the latest liveness heartbeat
+func (c *StatsCheckpoint) RecordHeartbeat(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.LastHeartbeat = at
+}
+
+// RecordLinkageBreak counts a parent blockhash linkage break of source
+func (c *StatsCheckpoint) RecordLinkageBreak(source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats.LinkageBreaks == nil {
+		c.stats.LinkageBreaks = map[string]int{}
+	}
+	c.stats.LinkageBreaks[source]++
+}
+
+// RecordError counts a comparison that failed before producing a result
+func (c *StatsCheckpoint) RecordError() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Errors++
+}
+
+// MatchRate returns the current SLA counter pair
+func (c *StatsCheckpoint) MatchRate() MatchRateCounter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats.MatchRate
+}
+
+// ResetMatchRate restarts the SLA counter pair
+func (c *StatsCheckpoint) ResetMatchRate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.MatchRate.Reset(time.Now())
+}
+
+// Flush writes the stats to the checkpoint file
+func (c *StatsCheckpoint) Flush() error {
+	c.mu.Lock()
+	c.stats.UpdatedAt = time.Now()
+	stats := c.stats
+	stats.MismatchedSlots = slices.Clone(c.stats.MismatchedSlots)
+	stats.Categories = maps.Clone(c.stats.Categories)
+	stats.LinkageBreaks = maps.Clone(c.stats.LinkageBreaks)
+	c.mu.Unlock()
+
+	return c.write(stats)
+}
+
+// write encodes stats to the checkpoint file, going through a temporary file so a crash
+// mid-write never leaves a truncated checkpoint behind
+func (c *StatsCheckpoint) write(stats any) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats checkpoint %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("failed to move stats checkpoint into place: %w", err)
+	}
+
+	return nil
+}
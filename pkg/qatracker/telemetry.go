@@ -0,0 +1,166 @@
+package qatracker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// telemetryPostTimeout bounds a telemetry report so an unreachable collector never stalls the tracker
+const telemetryPostTimeout = 10 * time.Second
+
+// TelemetryReport is the anonymized payload sent to the collector. It only holds aggregate
+// counts and endpoint kinds, never slots, block contents, endpoint URLs or credentials.
+type TelemetryReport struct {
+	// InstanceID is random per process, it only allows grouping reports of a same run
+	InstanceID       string         `json:"instance_id"`
+	PeriodStart      time.Time      `json:"period_start"`
+	PeriodEnd        time.Time      `json:"period_end"`
+	FirehoseEndpoint string         `json:"firehose_endpoint_kind"`
+	RPCEndpoint      string         `json:"rpc_endpoint_kind"`
+	Comparisons      int            `json:"comparisons"`
+	Mismatches       int            `json:"mismatches"`
+	MismatchRate     float64        `json:"mismatch_rate"`
+	Categories       map[string]int `json:"mismatches_by_category"`
+}
+
+// TelemetryReporter aggregates comparison results and periodically reports them, anonymized,
+// to a collector. It is strictly opt-in through --telemetry.
+type TelemetryReporter struct {
+	collectorURL string
+	interval     time.Duration
+	client       *http.Client
+	instanceID   string
+	firehoseKind string
+	rpcKind      string
+
+	mu          sync.Mutex
+	since       time.Time
+	comparisons int
+	mismatches  int
+	categories  map[string]int
+}
+
+// WithTelemetry enables the periodic anonymized telemetry report
+func WithTelemetry(reporter *TelemetryReporter) Option {
+	return func(t *Tracker) {
+		t.telemetry = reporter
+	}
+}
+
+// NewTelemetryReporter creates a TelemetryReporter posting to collectorURL every interval. The
+// endpoints are only used to derive their kind, they are never reported.
+func NewTelemetryReporter(collectorURL string, interval time.Duration, firehoseEndpoint, rpcEndpoint string) (*TelemetryReporter, error) {
+	if collectorURL == "" {
+		return nil, fmt.Errorf("a collector URL is required to enable telemetry")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("telemetry interval must be positive")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate telemetry instance id: %w", err)
+	}
+
+	return &TelemetryReporter{
+		collectorURL: collectorURL,
+		interval:     interval,
+		client:       &http.Client{Timeout: telemetryPostTimeout},
+		instanceID:   hex.EncodeToString(id),
+		firehoseKind: firehoseEndpointKind(firehoseEndpoint),
+		rpcKind:      rpcEndpointKind(rpcEndpoint),
+		since:        time.Now(),
+		categories:   map[string]int{},
+	}, nil
+}
+
+// firehoseEndpointKind anonymizes a Firehose endpoint to "streamingfast" or "self-hosted"
+func firehoseEndpointKind(endpoint string) string {
+	host, _, _ := strings.Cut(endpoint, ":")
+	if strings.HasSuffix(host, ".streamingfast.io") {
+		return "streamingfast"
+	}
+	return "self-hosted"
+}
+
+// rpcEndpointKind anonymizes an RPC endpoint to "solana-public" or "private"
+func rpcEndpointKind(endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	if err == nil && strings.HasSuffix(parsed.Hostname(), ".solana.com") {
+		return "solana-public"
+	}
+	return "private"
+}
+
+// Record adds a comparison result to the current reporting period
+func (r *TelemetryReporter) Record(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comparisons++
+	if result.Match {
+		return
+	}
+
+	r.mismatches++
+	for _, category := range result.DiffCategories {
+		r.categories[category]++
+	}
+}
+
+// Report posts the aggregate of the current period to the collector and starts a new period.
+// Nothing is sent when no comparison ran during the period.
+func (r *TelemetryReporter) Report(ctx context.Context) error {
+	r.mu.Lock()
+	report := TelemetryReport{
+		InstanceID:       r.instanceID,
+		PeriodStart:      r.since,
+		PeriodEnd:        time.Now(),
+		FirehoseEndpoint: r.firehoseKind,
+		RPCEndpoint:      r.rpcKind,
+		Comparisons:      r.comparisons,
+		Mismatches:       r.mismatches,
+		Categories:       r.categories,
+	}
+	r.since = report.PeriodEnd
+	r.comparisons = 0
+	r.mismatches = 0
+	r.categories = map[string]int{}
+	r.mu.Unlock()
+
+	if report.Comparisons == 0 {
+		return nil
+	}
+	report.MismatchRate = float64(report.Mismatches) / float64(report.Comparisons)
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.collectorURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry collector returned status %s", resp.Status)
+	}
+	return nil
+}
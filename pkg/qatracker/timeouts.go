@@ -0,0 +1,84 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	pbbstream "github.com/streamingfast/bstream/pb/sf/bstream/v1"
+)
+
+// WithFetchTimeouts bounds the Firehose fetch and the RPC fetch with independent deadlines,
+// since both sources have very different latency profiles (0 leaves a source unbounded)
+func WithFetchTimeouts(firehose, rpc time.Duration) Option {
+	return func(t *Tracker) {
+		t.firehoseFetchTimeout = firehose
+		t.rpcFetchTimeout = rpc
+	}
+}
+
+// withFetchTimeout derives the context of a single source fetch, bounded by timeout when positive
+func withFetchTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// fetchTimedOut reports whether a fetch failed because its own deadline expired, rather than
+// because the parent context was cancelled
+func fetchTimedOut(ctx, fetchCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded)
+}
+
+// WithComparisonTimeout bounds each periodic comparison, both fetches and the comparison itself,
+// so a comparison stuck on a slow source doesn't hold its slot until the next ones are skipped
+func WithComparisonTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.comparisonTimeout = timeout
+	}
+}
+
+// compareBlocksWithTimeout runs CompareBlocks bounded by the comparison timeout
+func (t *Tracker) compareBlocksWithTimeout(ctx context.Context) error {
+	compareCtx, cancel := withFetchTimeout(ctx, t.comparisonTimeout)
+	defer cancel()
+
+	err := t.CompareBlocks(compareCtx)
+	if err != nil && fetchTimedOut(ctx, compareCtx) {
+		return fmt.Errorf("%w: comparison timed out after %s: %w", ErrNetwork, t.comparisonTimeout, err)
+	}
+	return err
+}
+
+// rpcFetchResult is the outcome of an RPC fetcher call
+type rpcFetchResult struct {
+	block   *pbbstream.Block
+	skipped bool
+	err     error
+}
+
+// fetchRPCBlock calls the RPC fetcher, returning as soon as ctx is done even when the fetcher
+// doesn't observe it while retrying or decoding a large block. The abandoned call completes
+// in the background and its result is dropped.
+func (t *Tracker) fetchRPCBlock(ctx context.Context, slot uint64) (*pbbstream.Block, bool, error) {
+	return t.fetchRPCBlockFrom(ctx, t.rpcClient, slot)
+}
+
+// fetchRPCBlockFrom is fetchRPCBlock through the RPC client of any provider
+func (t *Tracker) fetchRPCBlockFrom(ctx context.Context, client *rpc.Client, slot uint64) (*pbbstream.Block, bool, error) {
+	resultC := make(chan rpcFetchResult, 1)
+	go func() {
+		block, skipped, err := t.rpcFetcher.Fetch(ctx, client, slot)
+		resultC <- rpcFetchResult{block: block, skipped: skipped, err: err}
+	}()
+
+	select {
+	case result := <-resultC:
+		return result.block, result.skipped, result.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
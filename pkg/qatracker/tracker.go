@@ -0,0 +1,1272 @@
+// Package qatracker compares Solana blocks fetched from StreamingFast Firehose and from an RPC
+// node, and reports the mismatches. It backs the tracker command and can be embedded in other
+// programs.
+package qatracker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mostynb/go-grpc-compression/zstd"
+	pbbstream "github.com/streamingfast/bstream/pb/sf/bstream/v1"
+	"github.com/streamingfast/dstore"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxStreamEOFRetries is the number of attempts made when Firehose closes the stream cleanly
+// or stalls
+const maxStreamEOFRetries = 3
+
+// RPCFetcher interface for block fetching
+type RPCFetcher interface {
+	Fetch(ctx context.Context, client *rpc.Client, requestedSlot uint64) (b *pbbstream.Block, skipped bool, err error)
+}
+
+// Tracker manages RPC clients, logger, and block comparison operations
+type Tracker struct {
+	logger            *zap.Logger
+	firehoseEndpoint  string
+	solanaRPCEndpoint string
+	// Firehose credentials, the JWT takes precedence over the API key
+	firehoseAPIToken string
+	firehoseAPIKey   string
+	// Reusable clients, the Firehose connection and credentials are replaced on reconnection
+	firehoseMu     sync.RWMutex
+	firehoseConn   *grpc.ClientConn
+	firehoseClient pbfirehose.StreamClient
+	rpcFetcher     RPCFetcher
+	rpcClient      *rpc.Client
+	// Additional RPC providers the Firehose block is compared with (empty when disabled), and
+	// the number of agreeing providers defining the expected block (0 when the primary decides)
+	rpcProviders []rpcProvider
+	rpcQuorum    int
+	// Reader options the RPC fetcher mirrors
+	fetcherConfig FetcherConfig
+	// Artifact output settings, artifacts go to the output store when set and to the output
+	// directory otherwise
+	outputStore    dstore.Store
+	artifactFormat ArtifactFormat
+	// Links to the output store artifacts shown in notifications, public or signed (both
+	// empty when notifications show the object URLs)
+	artifactPublicURL  string
+	artifactURLSigner  ArtifactURLSigner
+	artifactScope      ArtifactScope
+	artifactProtoNames bool
+	outputDir          string
+	outputPrefix       string
+	// Rolling mismatch rate tracking (nil when disabled)
+	mismatchRate *mismatchRateWindow
+	// Only compare transactions invoking this program (nil when disabled)
+	filterProgram []byte
+	// Slack receives every alert, mismatch events are also fanned out to the other notifiers
+	slack     *SlackNotifier
+	notifiers []Notifier
+	// Only notify divergence state transitions, with the state tracked while enabled
+	notifyOnRecoveryOnly bool
+	// Coalescing of mismatch alerts within a cooldown window (nil when disabled)
+	alertCooldown      *alertCooldown
+	health             healthState
+	divergedSince      time.Time
+	divergedMismatches int
+	// Periodic email digest of comparison results (nil when disabled)
+	emailNotifier *EmailNotifier
+	// Periodic checkpoint of aggregate stats to disk (nil when disabled)
+	statsCheckpoint *StatsCheckpoint
+	// Compared-slot state persisted after every comparison (nil when disabled)
+	slotState *SlotState
+	// Opt-in anonymized telemetry report (nil when disabled)
+	telemetry *TelemetryReporter
+	// Compare two decoders on the same Firehose bytes instead of Firehose against RPC
+	decoderCheck bool
+	// Ignore transaction ordering in the checksum comparison
+	normalizeOrder bool
+	// Maximum BlockTime difference in seconds not reported as a mismatch
+	blockTimeTolerance int64
+	// Projection of the block covered by comparison checksums
+	checksumScope ChecksumScope
+	// How sanitized fields are cleared in mismatch artifacts
+	sanitizeMode SanitizeMode
+	// Transaction meta fields stripped before checksumming
+	ignoredFields IgnoredFields
+	// Minimum available memory in bytes required to start a comparison (0 disables the guard)
+	minFreeMemory uint64
+	// Compare the latest finalized slot resolved through RPC instead of the Firehose head
+	compareFinalized bool
+	// Number of newest finalized slots compared per tick (0 compares the head block only) and
+	// highest slot compared so far in batch mode
+	batchSize      int
+	batchHighWater atomic.Uint64
+	// Give up once more than maxConsecutiveErrors comparisons in a row failed (0 disables)
+	maxConsecutiveErrors int
+	consecutiveErrors    atomic.Int64
+	// JSON-RPC batching of getBlock calls in streamed comparisons (nil when disabled)
+	rpcBatchSize int
+	rpcBatcher   *batchingRPCClient
+	// Interval of the liveness heartbeat (0 disables) and Unix time of the last one
+	heartbeatInterval time.Duration
+	lastHeartbeat     atomic.Int64
+	// Parent blockhash linkage check within each source (nil when disabled)
+	linkage *blockLinkage
+	// Source of all randomness, seeded for reproducibility
+	rand *seededRand
+	// Bounds the number of comparisons running simultaneously
+	comparisonSlots chan struct{}
+	// Serializes result consumers when comparisons run concurrently
+	publishMu sync.Mutex
+	// Maximum time given to in-flight work when shutting down
+	shutdownTimeout time.Duration
+	// Wait for the Firehose channel to be ready (bounded by firehoseReadyTimeout)
+	firehoseWaitForReady bool
+	firehoseReadyTimeout time.Duration
+	// Independent deadlines of the Firehose and RPC fetches (0 disables)
+	firehoseFetchTimeout time.Duration
+	rpcFetchTimeout      time.Duration
+	// Deadline of a whole periodic comparison, both fetches included (0 disables)
+	comparisonTimeout time.Duration
+	// Reconnect the Firehose stream when no block arrives within this timeout (0 disables)
+	firehoseRecvTimeout time.Duration
+	// Firehose reconnections on connection-level errors (0 disables), re-reading the
+	// credentials from the environment when refreshFirehoseCredentials is set
+	firehoseReconnectAttempts  int
+	refreshFirehoseCredentials bool
+	// Firehose head slot advancement tracking (nil when disabled)
+	headLiveness *headLiveness
+	// Prefetch of upcoming RPC fetcher blocks in streamed comparisons (nil when disabled)
+	prefetcher *rpcPrefetcher
+	// Number of blocks of a range or follow stream compared in parallel
+	concurrency int
+	// Archived merged blocks compared against a live source (nil when disabled)
+	blocksStore *BlocksStore
+	// Second Firehose endpoint compared against instead of the RPC fetcher (nil when disabled)
+	peerFirehose *PeerFirehose
+	// Live source compared against instead of the RPC fetcher (nil when disabled)
+	blockSource BlockSource
+	// Raw bytes of the blocks being compared, dumped on mismatch (nil when disabled)
+	rawBlocks *rawBlockBytes
+	// How slots skipped by one or both sources are classified
+	skippedSlotPolicy SkippedSlotPolicy
+	// Write the field diffs of mismatches to diff_<slot>.json
+	writeDiffFile bool
+	// Prometheus metrics (nil when disabled)
+	metrics *Metrics
+	// Liveness and readiness probes (nil when disabled)
+	probes *Probes
+	// gRPC service streaming the comparison results (nil when disabled)
+	grpcServer *GRPCServer
+	// JSON lines log of every comparison result (nil when disabled)
+	resultsLog *ResultsLog
+	// Source fetch durations kept until their result is published (nil when not stored)
+	fetchDurations *fetchDurations
+	// Background artifact writer queue (nil when artifacts are written synchronously)
+	artifactQueue *artifactQueue
+	// Results store sink (nil when disabled) and results buffered for its next batch insert
+	resultsSink      ResultsSink
+	resultsBatchSize int
+	pendingResults   []Result
+	// Number of differing transactions reported with a mismatch (0 disables per-transaction checksums)
+	maxDifferingTransactions int
+}
+
+// Result holds the outcome of a single block comparison
+type Result struct {
+	Slot               uint64      `json:"slot"`
+	FirehoseChecksum   string      `json:"firehose_checksum"`
+	RPCFetcherChecksum string      `json:"rpc_fetcher_checksum"`
+	Match              bool        `json:"match"`
+	OrderMatch         bool        `json:"order_match"`
+	DiffCategories     []string    `json:"diff_categories,omitempty"`
+	FieldDiffs         []FieldDiff `json:"field_diffs,omitempty"`
+	// DifferingTransactions are the first transactions whose sanitized checksums differ, out of
+	// DifferingTransactionCount
+	DifferingTransactions     []TransactionDiff `json:"differing_transactions,omitempty"`
+	DifferingTransactionCount int               `json:"differing_transaction_count,omitempty"`
+	FirehoseFile              string            `json:"firehose_file,omitempty"`
+	RPCFetcherFile            string            `json:"rpc_fetcher_file,omitempty"`
+	Skipped                   bool              `json:"skipped,omitempty"`
+	// Providers compares the Firehose block with every RPC provider, the primary endpoint first,
+	// when additional providers are configured
+	Providers []ProviderResult `json:"providers,omitempty"`
+	// FirehoseFetchMs and RPCFetcherFetchMs are the source fetch durations, set when the results
+	// log or a results sink is enabled and the fetch was measured
+	FirehoseFetchMs   int64     `json:"firehose_fetch_ms,omitempty"`
+	RPCFetcherFetchMs int64     `json:"rpc_fetcher_fetch_ms,omitempty"`
+	Time              time.Time `json:"time"`
+}
+
+// Option configures optional Tracker behavior
+type Option func(*Tracker)
+
+// WithArtifactFormat sets the format used when dumping mismatching blocks
+func WithArtifactFormat(format ArtifactFormat) Option {
+	return func(t *Tracker) {
+		t.artifactFormat = format
+	}
+}
+
+// WithArtifactProtoNames makes JSON artifacts use the proto snake_case field names
+func WithArtifactProtoNames() Option {
+	return func(t *Tracker) {
+		t.artifactProtoNames = true
+	}
+}
+
+// WithFirehoseWaitForReady makes Firehose calls wait up to timeout for the channel to become
+// ready instead of failing immediately when the backend is transiently unavailable
+func WithFirehoseWaitForReady(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.firehoseWaitForReady = true
+		t.firehoseReadyTimeout = timeout
+	}
+}
+
+// WithFirehoseAuth sets the Firehose credentials, a JWT token or an API key (the token wins when
+// both are set)
+func WithFirehoseAuth(token, apiKey string) Option {
+	return func(t *Tracker) {
+		t.firehoseAPIToken = token
+		t.firehoseAPIKey = apiKey
+	}
+}
+
+// WithRPCFetcher replaces the default firehose-solana RPC fetcher, e.g. with a mock,
+// a caching wrapper or a different fetcher version
+func WithRPCFetcher(f RPCFetcher) Option {
+	return func(t *Tracker) {
+		t.rpcFetcher = f
+	}
+}
+
+// WithMismatchRateAlert enables an alert when the mismatch rate over the last
+// window comparisons reaches threshold percent
+func WithMismatchRateAlert(window int, threshold float64) Option {
+	return func(t *Tracker) {
+		t.mismatchRate = newMismatchRateWindow(window, threshold)
+	}
+}
+
+// NewTracker creates a new Tracker instance with the provided configuration, the caller must
+// Close it once done
+func NewTracker(logger *zap.Logger, slackWebhookURL, slackChannel, firehoseEndpoint, solanaRPCEndpoint string, opts ...Option) (*Tracker, error) {
+	// Create gRPC connection for firehose (will be reused until a reconnection)
+	conn, err := dialFirehose(firehoseEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Firehose: %w", err)
+	}
+
+	// Create Firehose client (will be reused)
+	firehoseClient := pbfirehose.NewStreamClient(conn)
+
+	t := &Tracker{
+		logger:            logger,
+		firehoseEndpoint:  firehoseEndpoint,
+		solanaRPCEndpoint: solanaRPCEndpoint,
+		// Initialize reusable clients
+		firehoseConn:             conn,
+		firehoseClient:           firehoseClient,
+		artifactFormat:           ArtifactFormatJSON,
+		artifactScope:            ArtifactScopeFull,
+		sanitizeMode:             SanitizeModeNull,
+		checksumScope:            ChecksumScopeFull,
+		outputDir:                ".",
+		shutdownTimeout:          30 * time.Second,
+		resultsBatchSize:         1,
+		maxDifferingTransactions: defaultMaxDifferingTransactions,
+		concurrency:              1,
+		comparisonSlots:          make(chan struct{}, 1),
+		fetcherConfig:            DefaultFetcherConfig(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.resultsLog != nil || t.resultsSink != nil {
+		t.fetchDurations = newFetchDurations()
+	}
+	if t.ignoredFields == nil {
+		// The default paths always resolve
+		t.ignoredFields, _ = ParseIgnoredFields(DefaultIgnoredFields)
+	}
+
+	// Every log line carries the audited network
+	logger = logger.With(zap.String("network", t.networkLabel()))
+	t.logger = logger
+
+	// Slack comes first, it also receives the alerts other than mismatches
+	t.slack = NewSlackNotifier(slackWebhookURL, slackChannel, logger)
+	t.notifiers = append([]Notifier{t.slack}, t.notifiers...)
+
+	// Create RPC client (will be reused)
+	t.rpcClient = t.newRPCClient()
+
+	// Generate a seed when none was given, it's logged so the run can be reproduced
+	seedGenerated := t.rand == nil
+	if seedGenerated {
+		t.rand = newSeededRand(rand.Uint64())
+	}
+	logger.Info("Random seed", zap.Uint64("seed", t.rand.seed), zap.Bool("generated", seedGenerated))
+
+	// Create RPCFetcher instance (will be reused) unless one was injected
+	if t.rpcFetcher == nil {
+		t.rpcFetcher = t.fetcherConfig.newRPCFetcher(logger)
+	}
+
+	if t.blockSource != nil {
+		if err := t.blockSource.Start(logger, t.fetcherConfig.Commitment); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start block source %s: %w", t.blockSource.Name(), err)
+		}
+	}
+
+	if t.artifactQueue != nil {
+		go t.runArtifactWriter()
+	}
+
+	return t, nil
+}
+
+// Close releases the Firehose connection and closes the results log and sink
+func (t *Tracker) Close() error {
+	var errs []error
+	if t.resultsLog != nil {
+		if err := t.resultsLog.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close results log: %w", err))
+		}
+	}
+	if t.resultsSink != nil {
+		if err := t.resultsSink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close results sink: %w", err))
+		}
+	}
+
+	if t.peerFirehose != nil {
+		if err := t.peerFirehose.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close peer Firehose connection: %w", err))
+		}
+	}
+	if t.blockSource != nil {
+		if err := t.blockSource.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close block source %s: %w", t.blockSource.Name(), err))
+		}
+	}
+
+	t.firehoseMu.Lock()
+	defer t.firehoseMu.Unlock()
+
+	if err := t.firehoseConn.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close Firehose connection: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// CloseLogged closes the tracker when a command returns, a failure is only logged since the
+// command's own result matters more
+func (t *Tracker) CloseLogged() {
+	if err := t.Close(); err != nil {
+		t.logger.Warn("Failed to close tracker", zap.Error(err))
+	}
+}
+
+// highSeverityLine returns the notification line listing high severity differences (at most
+// maxHighSeverityPaths of them), or an empty string when there are none
+func highSeverityLine(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	shown := paths[:min(len(paths), maxHighSeverityPaths)]
+	line := fmt.Sprintf("• *High severity*: %d account key mismatches (`%s`", len(paths), strings.Join(shown, "`, `"))
+	if len(paths) > len(shown) {
+		line += ", ..."
+	}
+	return line + ")\n"
+}
+
+// differingPathsLine returns the notification line listing the differing field paths (at most
+// maxDifferingPaths of them), or an empty string when there are none
+func differingPathsLine(diffs []FieldDiff) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	paths := make([]string, 0, min(len(diffs), maxDifferingPaths))
+	for _, diff := range diffs[:min(len(diffs), maxDifferingPaths)] {
+		paths = append(paths, diff.Path)
+	}
+	line := fmt.Sprintf("• Differing fields: %d (`%s`", len(diffs), strings.Join(paths, "`, `"))
+	if len(diffs) > len(paths) {
+		line += ", ..."
+	}
+	return line + ")\n"
+}
+
+// environmentLine returns the notification line identifying the environment through the
+// output prefix and the network, or an empty string for mainnet without a prefix
+func (t *Tracker) environmentLine() string {
+	return environmentLine(strings.TrimRight(t.outputPrefix, "-_."), t.networkLabel())
+}
+
+// environmentLine returns the notification line identifying an environment and its network
+func environmentLine(environment, network string) string {
+	switch {
+	case environment != "":
+		return fmt.Sprintf("Environment: `%s` (%s)\n", environment, network)
+	case network != "mainnet":
+		return fmt.Sprintf("Network: `%s`\n", network)
+	default:
+		return ""
+	}
+}
+
+// postSlackMessage posts a raw text message to the configured Slack webhook
+func (t *Tracker) postSlackMessage(message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	return t.slack.post(ctx, message)
+}
+
+// ApiKeyAuth implements per-RPC credentials using API key
+type ApiKeyAuth struct {
+	ApiKey string
+}
+
+func (a *ApiKeyAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+	out := make(map[string]string)
+	for k, v := range md {
+		if len(v) != 0 {
+			out[k] = v[0]
+		}
+	}
+	if a.ApiKey != "" {
+		out["x-api-key"] = a.ApiKey
+	}
+	return out, nil
+}
+
+func (a *ApiKeyAuth) RequireTransportSecurity() bool {
+	return true
+}
+
+// calculateChecksum calculates SHA256 checksum of the given data
+func calculateChecksum(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// sanitizeBlock clears the ignored fields from the block (modifies original). Cleared fields are
+// absent from JSON artifacts, unless they're emitted unpopulated with --sanitize-mode empty.
+func sanitizeBlock(block *pbsol.Block, fields IgnoredFields) {
+	fields.clear(block.ProtoReflect())
+}
+
+// calculateSanitizedChecksum calculates checksum of a block after clearing the ignored fields
+func calculateSanitizedChecksum(block *pbsol.Block, fields IgnoredFields) (string, error) {
+	// Sanitize the block by clearing the ignored fields (modifies the original block)
+	sanitizeBlock(block, fields)
+
+	// Marshal the sanitized block to bytes
+	sanitizedData, err := proto.Marshal(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sanitized block: %w", err)
+	}
+
+	// Calculate checksum of sanitized data
+	return calculateChecksum(sanitizedData), nil
+}
+
+// calculateRawChecksum calculates checksum of a block before any field is ignored, comparing it
+// with the sanitized one tells whether a difference is only in ignored fields
+func calculateRawChecksum(block *pbsol.Block) (string, error) {
+	data, err := proto.Marshal(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal raw block: %w", err)
+	}
+	return calculateChecksum(data), nil
+}
+
+// fetchLatestBlock fetches and unmarshals the latest Solana block from StreamingFast Firehose
+func (t *Tracker) fetchLatestBlock(ctx context.Context) (*pbsol.Block, string, error) {
+	start := time.Now()
+	defer t.observeFirehoseFetch(start)
+
+	resp, err := t.fetchLatestResponse(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	block, checksum, err := t.decodeFirehoseResponse(resp)
+	if err != nil {
+		return nil, "", err
+	}
+	t.recordFirehoseFetch(block.Slot, start)
+
+	return block, checksum, nil
+}
+
+// fetchLatestResponse fetches the raw Firehose response carrying the latest block
+func (t *Tracker) fetchLatestResponse(ctx context.Context) (*pbfirehose.Response, error) {
+	// Create a request to get the latest blocks (following official pattern)
+	req := &pbfirehose.Request{
+		StartBlockNum:   -1,                                // Start from head (latest block)
+		StopBlockNum:    0,                                 // Stream indefinitely
+		FinalBlocksOnly: t.fetcherConfig.finalBlocksOnly(), // Match the RPC commitment
+	}
+
+	return t.fetchFirehoseResponse(ctx, req)
+}
+
+// fetchFirehoseResponse returns the first response of the Firehose stream opened with req,
+// bounded by the Firehose fetch timeout
+func (t *Tracker) fetchFirehoseResponse(ctx context.Context, req *pbfirehose.Request) (*pbfirehose.Response, error) {
+	fetchCtx, cancel := withFetchTimeout(ctx, t.firehoseFetchTimeout)
+	defer cancel()
+
+	// With wait-for-ready the call blocks until the channel is ready, bound it so a backend
+	// that never comes back can't block the comparison forever
+	streamCtx := fetchCtx
+	if t.firehoseWaitForReady {
+		var cancelReady context.CancelFunc
+		streamCtx, cancelReady = context.WithTimeout(fetchCtx, t.firehoseReadyTimeout)
+		defer cancelReady()
+	}
+
+	// Get the first block, reconnecting if the server closes the stream cleanly or the
+	// connection fails
+	resp, err := t.receiveFirstBlockReconnecting(streamCtx, req)
+	if err != nil {
+		if fetchTimedOut(ctx, fetchCtx) {
+			return nil, fmt.Errorf("%w: Firehose fetch timed out after %s: %w", ErrNetwork, t.firehoseFetchTimeout, err)
+		}
+		return nil, classifyFirehoseError(err)
+	}
+	return resp, nil
+}
+
+// firehoseCallOptions builds the authentication, compression and readiness call options for Firehose
+func (t *Tracker) firehoseCallOptions() []grpc.CallOption {
+	// Setup call options for authentication and compression
+	var callOpts []grpc.CallOption
+	t.firehoseMu.RLock()
+	defer t.firehoseMu.RUnlock()
+	if t.firehoseAPIToken != "" {
+		credentials := oauth.NewOauthAccess(&oauth2.Token{AccessToken: t.firehoseAPIToken, TokenType: "Bearer"})
+		callOpts = append(callOpts, grpc.PerRPCCredentials(credentials))
+	} else if t.firehoseAPIKey != "" {
+		callOpts = append(callOpts, grpc.PerRPCCredentials(&ApiKeyAuth{ApiKey: t.firehoseAPIKey}))
+	}
+
+	// Add compression support (zstd is preferred by firehose servers)
+	callOpts = append(callOpts, grpc.UseCompressor(zstd.Name))
+
+	// Block until the channel is ready instead of failing fast on transient unavailability
+	if t.firehoseWaitForReady {
+		callOpts = append(callOpts, grpc.WaitForReady(true))
+	}
+
+	return callOpts
+}
+
+// decodeFirehoseResponse unmarshals the Solana block carried by a Firehose response and
+// computes its sanitized checksum
+func (t *Tracker) decodeFirehoseResponse(resp *pbfirehose.Response) (*pbsol.Block, string, error) {
+	// Extract basic block information
+	block := resp.Block
+	if block == nil {
+		return nil, "", fmt.Errorf("%w: received empty block", ErrDecode)
+	}
+
+	// Unmarshall the block data into Solana Block structure first
+	var solanaBlock pbsol.Block
+	err := proto.Unmarshal(block.Value, &solanaBlock)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: failed to unmarshall Solana block: %w", ErrDecode, err)
+	}
+	t.keepRawBytes(&solanaBlock, block.Value)
+
+	if t.filterProgram != nil {
+		filterBlockByProgram(&solanaBlock, t.filterProgram)
+	}
+
+	rawChecksum, err := calculateRawChecksum(&solanaBlock)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Calculate sanitized checksum (without the ignored fields) over the configured scope
+	checksum, err := calculateScopedChecksum(&solanaBlock, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to calculate sanitized checksum: %v", err)
+	}
+	t.logger.Info("Firehose block sanitized checksum calculated", zap.String("checksum_sha256", checksum), zap.String("raw_checksum_sha256", rawChecksum))
+
+	return &solanaBlock, checksum, nil
+}
+
+// receiveFirstBlock opens a Firehose stream and returns its first response. A server that
+// closes the stream cleanly (io.EOF) is normal stream cycling, so we reconnect and retry
+// instead of failing the comparison.
+func (t *Tracker) receiveFirstBlock(ctx context.Context, req *pbfirehose.Request, callOpts []grpc.CallOption) (*pbfirehose.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.receiveFirstBlockOnce(ctx, req, callOpts)
+		if err == nil {
+			return resp, nil
+		}
+
+		if !(errors.Is(err, io.EOF) || errors.Is(err, errStreamStalled)) || attempt >= maxStreamEOFRetries {
+			return nil, err
+		}
+
+		t.logger.Debug("Firehose stream ended before first block, reconnecting", zap.Int("attempt", attempt), zap.Error(err))
+	}
+}
+
+// receiveFirstBlockOnce opens a single Firehose stream and returns its first response
+func (t *Tracker) receiveFirstBlockOnce(ctx context.Context, req *pbfirehose.Request, callOpts []grpc.CallOption) (*pbfirehose.Response, error) {
+	// Only the first block is needed, cancel the stream once it has been received
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Create stream with call options using reusable client
+	_, firehoseClient := t.firehoseConnection()
+	stream, err := firehoseClient.Blocks(streamCtx, req, callOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream: %w", err)
+	}
+
+	resp, err := t.recvWithWatchdog(stream, cancel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive block: %w", err)
+	}
+
+	return resp, nil
+}
+
+// fetchBlockWithRPCFetcher fetches the same block using the block fetcher from firehose-solana
+func (t *Tracker) fetchBlockWithRPCFetcher(ctx context.Context, slot uint64) (*pbsol.Block, string, error) {
+	start := time.Now()
+	defer t.observeRPCFetch(start)
+
+	fetchCtx, cancel := withFetchTimeout(ctx, t.rpcFetchTimeout)
+	defer cancel()
+
+	// Use reusable RPCFetcher and RPC client instances
+	// Fetch the block using reusable RPCFetcher and RPC client
+	block, skipped, err := t.fetchRPCBlock(fetchCtx, slot)
+	if err != nil {
+		if fetchTimedOut(ctx, fetchCtx) {
+			return nil, "", fmt.Errorf("%w: RPC fetch timed out after %s: %w", ErrNetwork, t.rpcFetchTimeout, err)
+		}
+		if isTransactionVersionError(err) {
+			return nil, "", fmt.Errorf("%w: slot %d holds transactions of a version above --rpc-max-tx-version %d, raise it to compare this block: %w",
+				ErrUnsupportedTransactionVersion, slot, t.fetcherConfig.MaxSupportedTransactionVersion, err)
+		}
+		return nil, "", classifyRPCFetcherError(fmt.Errorf("failed to fetch block with RPCFetcher: %w", err))
+	}
+
+	if skipped {
+		return nil, "", fmt.Errorf("%w: block %d was skipped", ErrSkipped, slot)
+	}
+
+	solanaBlock, err := decodeRPCFetcherBlock(block, slot)
+	if err != nil {
+		return nil, "", err
+	}
+	t.keepRawBytes(solanaBlock, block.Payload.Value)
+
+	if t.filterProgram != nil {
+		filterBlockByProgram(solanaBlock, t.filterProgram)
+	}
+
+	rawChecksum, err := calculateRawChecksum(solanaBlock)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Calculate sanitized checksum (without the ignored fields) over the configured scope
+	checksum, err := calculateScopedChecksum(solanaBlock, t.checksumScope, t.ignoredFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to calculate sanitized checksum: %w", err)
+	}
+	t.logger.Info("RPCFetcher block sanitized checksum calculated", zap.String("checksum_sha256", checksum), zap.String("raw_checksum_sha256", rawChecksum))
+	t.recordRPCFetcherFetch(slot, start)
+
+	return solanaBlock, checksum, nil
+}
+
+// decodeRPCFetcherBlock extracts the pbsol.Block from the pbbstream.Block payload of the RPC
+// fetcher
+func decodeRPCFetcherBlock(block *pbbstream.Block, slot uint64) (*pbsol.Block, error) {
+	if block.Payload == nil {
+		return nil, fmt.Errorf("%w: block payload is nil", ErrDecode)
+	}
+
+	// A zero-length payload unmarshals into an empty block which could checksum-match an empty
+	// Firehose block and mask data loss, so it's reported as an anomaly instead
+	if len(block.Payload.Value) == 0 {
+		return nil, fmt.Errorf("%w: block %d payload is present but zero-length", ErrDecode, slot)
+	}
+
+	var solanaBlock pbsol.Block
+	if err := proto.Unmarshal(block.Payload.Value, &solanaBlock); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal Solana block: %w", ErrDecode, err)
+	}
+	return &solanaBlock, nil
+}
+
+// writeBlocksToJSONFiles writes both pbsol.Block objects to separate JSON files using the given
+// protojson options
+func (t *Tracker) writeBlocksToJSONFiles(block1, block2 *pbsol.Block, filename1, filename2 string, marshaler protojson.MarshalOptions) error {
+	// Marshal first block
+	json1, err := marshaler.Marshal(block1)
+	if err != nil {
+		return fmt.Errorf("failed to marshal first block to JSON: %w", err)
+	}
+
+	// Marshal second block
+	json2, err := marshaler.Marshal(block2)
+	if err != nil {
+		return fmt.Errorf("failed to marshal second block to JSON: %w", err)
+	}
+
+	// Write first block to file
+	err = t.writeArtifactFile(filename1, json1)
+	if err != nil {
+		return fmt.Errorf("failed to write first block to file %s: %w", filename1, err)
+	}
+
+	// Write second block to file
+	err = t.writeArtifactFile(filename2, json2)
+	if err != nil {
+		return fmt.Errorf("failed to write second block to file %s: %w", filename2, err)
+	}
+
+	return nil
+}
+
+// CompareBlocks runs one comparison of the Firehose head block, or of the configured batch, and
+// publishes its results
+func (t *Tracker) CompareBlocks(ctx context.Context) error {
+	if t.decoderCheck {
+		_, err := t.compareDecoders(ctx)
+		return err
+	}
+	if t.batchSize > 0 {
+		return t.compareBatch(ctx)
+	}
+	if t.compareFinalized {
+		return t.compareLatestFinalized(ctx)
+	}
+
+	// Fetch the latest block from Firehose
+	t.logger.Info("Fetching latest block from StreamingFast Firehose")
+	firehoseBlock, firehoseBlockSum, err := t.fetchLatestBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching block from Firehose: %w", err)
+	}
+
+	t.logger.Info("Successfully fetched Firehose block", zap.Uint64("slot", firehoseBlock.Slot))
+	t.observeHeadSlot(firehoseBlock)
+
+	_, err = t.compareHeadBlock(ctx, firehoseBlock, firehoseBlockSum)
+	return err
+}
+
+// compareWithRPCFetcher fetches the Firehose block's slot through the RPC fetcher, compares
+// both sanitized checksums and handles artifacts and notifications on mismatch. A slot the RPC
+// fetcher reports as skipped returns an ErrSkipped error, whether that's expected depends on
+// the caller.
+func (t *Tracker) compareWithRPCFetcher(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string) (*Result, error) {
+	defer t.takeRawBytes(firehoseBlock)
+
+	if t.blocksStore != nil {
+		return t.compareWithBlocksStore(ctx, firehoseBlock, firehoseBlockSum)
+	}
+	if t.peerFirehose != nil {
+		return t.compareWithPeerFirehose(ctx, firehoseBlock, firehoseBlockSum)
+	}
+	if t.blockSource != nil {
+		return t.compareWithBlockSource(ctx, firehoseBlock, firehoseBlockSum)
+	}
+
+	// Now fetch the same block using the block fetcher from firehose-solana
+	t.logger.Info("Fetching block using RPCFetcher", zap.Uint64("slot", firehoseBlock.Slot))
+	rpcFetcherBlock, rpcFetcherBlockSum, err := t.fetchRPCFetcherBlock(ctx, firehoseBlock.Slot)
+
+	return t.compareRPCFetcherBlock(ctx, firehoseBlock, firehoseBlockSum, rpcFetcherBlock, rpcFetcherBlockSum, err)
+}
+
+// compareRPCFetcherBlock compares the Firehose block with the RPC fetcher block of the same slot,
+// and with the additional RPC providers, or reports rpcFetcherErr when the RPC fetch failed
+func (t *Tracker) compareRPCFetcherBlock(ctx context.Context, firehoseBlock *pbsol.Block, firehoseBlockSum string, rpcFetcherBlock *pbsol.Block, rpcFetcherBlockSum string, rpcFetcherErr error) (*Result, error) {
+	if rpcFetcherErr != nil {
+		if category := missingSlotCategory(rpcFetcherErr); category != "" {
+			t.reportMissingSlot(firehoseBlock, category, rpcFetcherErr)
+		}
+		return nil, fmt.Errorf("error fetching block with RPCFetcher: %w", rpcFetcherErr)
+	}
+	defer t.takeRawBytes(rpcFetcherBlock)
+
+	// The block is only trusted to be of the requested slot once compareFetchedBlocks checked it
+	t.logger.Info("Fetched block using RPCFetcher",
+		zap.Uint64("requested_slot", firehoseBlock.Slot),
+		zap.Uint64("slot", rpcFetcherBlock.Slot),
+		zap.String("block_hash", rpcFetcherBlock.Blockhash))
+
+	providers := t.compareProviders(ctx, firehoseBlock, firehoseBlockSum, rpcFetcherBlockSum)
+	return t.compareFetchedBlocks(firehoseBlock, firehoseBlockSum, rpcFetcherBlock, rpcFetcherBlockSum, "firehose_block", "rpc_fetcher_block", providers)
+}
+
+// compareFetchedBlocks compares the sanitized checksums of the Firehose block and the block
+// obtained from the second source, writing artifacts (named after firehoseArtifactPrefix and
+// otherArtifactPrefix) and notifying on mismatch. providers, when not nil, is the comparison
+// with the RPC providers whose first entry, the second source, gets the outcome.
+func (t *Tracker) compareFetchedBlocks(firehoseBlock *pbsol.Block, firehoseBlockSum string, rpcFetcherBlock *pbsol.Block, rpcFetcherBlockSum string, firehoseArtifactPrefix, otherArtifactPrefix string, providers []ProviderResult) (*Result, error) {
+	// Guard against the second source returning a different slot (redirect, off-by-one, caching),
+	// which would otherwise show up as a bogus mismatch between two different blocks
+	firehoseSource, otherSource := linkageSource(firehoseArtifactPrefix), linkageSource(otherArtifactPrefix)
+	if err := checkSlotConsistency(firehoseBlock, rpcFetcherBlock, firehoseSource, otherSource); err != nil {
+		t.reportSlotMismatch(firehoseBlock, rpcFetcherBlock, firehoseSource, otherSource, err)
+		return nil, err
+	}
+
+	t.checkLinkage(firehoseSource, firehoseBlock)
+	t.checkLinkage(otherSource, rpcFetcherBlock)
+
+	// Check transaction ordering separately from value equality
+	orderMatch := sameTransactionOrder(firehoseBlock, rpcFetcherBlock)
+	if !orderMatch {
+		t.logger.Warn("Transaction ordering differs between sources",
+			zap.Uint64("slot", firehoseBlock.Slot),
+			zap.Bool("normalize_order", t.normalizeOrder))
+
+		if t.normalizeOrder {
+			var err error
+			if firehoseBlockSum, err = calculateNormalizedChecksum(firehoseBlock, t.checksumScope, t.ignoredFields); err != nil {
+				return nil, err
+			}
+			if rpcFetcherBlockSum, err = calculateNormalizedChecksum(rpcFetcherBlock, t.checksumScope, t.ignoredFields); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if t.blockTimeTolerance > 0 {
+		var err error
+		rpcFetcherBlockSum, err = t.applyBlockTimeTolerance(firehoseBlock, rpcFetcherBlock, rpcFetcherBlockSum, !orderMatch && t.normalizeOrder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Compare checksums and only write to JSON files if they are not equal
+	t.logger.Info("Comparing checksums",
+		zap.String("firehose_checksum", firehoseBlockSum),
+		zap.String("rpc_fetcher_checksum", rpcFetcherBlockSum))
+
+	result := Result{
+		Slot:               firehoseBlock.Slot,
+		FirehoseChecksum:   firehoseBlockSum,
+		RPCFetcherChecksum: rpcFetcherBlockSum,
+		Match:              rpcFetcherBlockSum == firehoseBlockSum,
+		OrderMatch:         orderMatch,
+		Time:               time.Now(),
+	}
+
+	// The first block of an epoch carries the epoch rewards, it gets a stricter comparison
+	// that applies whatever the checksum scope
+	var epochDiffs []FieldDiff
+	if isEpochBoundary(firehoseBlock) {
+		epochDiffs = diffEpochBoundary(firehoseBlock, rpcFetcherBlock)
+		if len(epochDiffs) > 0 {
+			result.Match = false
+			t.logger.Error("Epoch boundary mismatch, rewards of the first block of the epoch differ",
+				zap.Uint64("slot", firehoseBlock.Slot),
+				zap.Uint64("epoch", epochOf(firehoseBlock.Slot)),
+				zap.Int("differences", len(epochDiffs)))
+		}
+	}
+
+	var quorumDiffs []FieldDiff
+	if providers != nil {
+		providers[0].Checksum, providers[0].Match = rpcFetcherBlockSum, result.Match
+		result.Providers = providers
+		t.logProviderDisagreements(result.Slot, providers)
+		quorumDiffs = t.applyQuorum(&result)
+	}
+
+	if !result.Match {
+		diffs := diffBlocks(firehoseBlock, rpcFetcherBlock)
+		if t.blockTimeTolerance > 0 && t.blockTimeTolerated(firehoseBlock, rpcFetcherBlock) {
+			diffs = slices.DeleteFunc(diffs, func(diff FieldDiff) bool { return diff.Category == "BlockTime" })
+		}
+		diffs = append(diffs, epochDiffs...)
+		diffs = append(diffs, quorumDiffs...)
+		result.DiffCategories = FieldDiffCategories(diffs)
+		result.FieldDiffs = diffs
+
+		if t.maxDifferingTransactions > 0 {
+			var err error
+			result.DifferingTransactions, result.DifferingTransactionCount, err = differingTransactions(firehoseBlock, rpcFetcherBlock, t.maxDifferingTransactions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		highSeverity := highSeverityPaths(diffs)
+		if len(highSeverity) > 0 {
+			t.logger.Error("High severity differences detected, account keys differ",
+				zap.Uint64("slot", firehoseBlock.Slot),
+				zap.Strings("paths", highSeverity))
+		}
+
+		t.logger.Warn("Checksums are different - writing blocks to artifact files",
+			zap.Uint64("slot", firehoseBlock.Slot),
+			zap.String("artifact_format", string(t.artifactFormat)),
+			zap.Strings("diff_categories", result.DiffCategories),
+			zap.Int("differing_transactions", result.DifferingTransactionCount),
+			zap.Any("first_differing_transactions", result.DifferingTransactions),
+			zap.Any("instruction_diffs_by_program", ProgramTally(diffs)))
+		if err := t.prepareOutput(); err != nil {
+			return nil, err
+		}
+		firehoseFilename := t.artifactPath(firehoseArtifactPrefix, firehoseBlock.Slot)
+		rpcFetcherFilename := t.artifactPath(otherArtifactPrefix, rpcFetcherBlock.Slot)
+
+		diffFilename := ""
+		if t.writeDiffFile {
+			var err error
+			diffFilename, err = t.writeFieldDiffs(firehoseBlock.Slot, diffs)
+			if err != nil {
+				return nil, err
+			}
+			t.logger.Info("Field diff file written", zap.String("diff_file", diffFilename), zap.Int("differences", len(diffs)))
+		}
+
+		firehoseArtifact, rpcFetcherArtifact := firehoseBlock, rpcFetcherBlock
+		if t.artifactScope == ArtifactScopeDiff {
+			firehoseArtifact = trimBlockToDiffs(firehoseBlock, diffs)
+			rpcFetcherArtifact = trimBlockToDiffs(rpcFetcherBlock, diffs)
+		}
+		job := artifactJob{
+			slot:              firehoseBlock.Slot,
+			firehoseBlock:     firehoseArtifact,
+			rpcFetcherBlock:   rpcFetcherArtifact,
+			firehoseFile:      firehoseFilename,
+			rpcFetcherFile:    rpcFetcherFilename,
+			firehoseRawName:   firehoseArtifactPrefix + "_raw",
+			rpcFetcherRawName: otherArtifactPrefix + "_raw",
+		}
+		if t.rawBlocks != nil {
+			job.firehoseRaw = t.takeRawBytes(firehoseBlock)
+			job.rpcFetcherRaw = t.takeRawBytes(rpcFetcherBlock)
+		}
+
+		switch {
+		case t.artifactQueue == nil:
+			if err := t.writeArtifacts(job); err != nil {
+				return nil, err
+			}
+		case !t.enqueueArtifacts(job):
+			t.logger.Warn("Artifact queue full, dropping block artifacts",
+				zap.Uint64("slot", firehoseBlock.Slot),
+				zap.Int("artifact_queue_size", cap(t.artifactQueue.jobs)))
+			firehoseFilename, rpcFetcherFilename = "", ""
+		}
+		result.FirehoseFile = firehoseFilename
+		result.RPCFetcherFile = rpcFetcherFilename
+		if firehoseFilename == "" {
+			firehoseFilename, rpcFetcherFilename = droppedArtifactLabel, droppedArtifactLabel
+		}
+
+		// Notify about the difference, unless only state transitions are notified or the alert is
+		// coalesced into the summary of the cooldown window
+		if t.notifyOnRecoveryOnly {
+			t.logger.Debug("Skipping per-mismatch notification, notifying on recovery only")
+		} else if t.admitAlert(firehoseBlock.Slot) {
+			event := t.mismatchEvent(firehoseBlock.Slot, firehoseBlockSum, rpcFetcherBlockSum, t.artifactLink(firehoseFilename), t.artifactLink(rpcFetcherFilename), diffs, len(epochDiffs) > 0)
+			event.DiffFile = t.artifactLink(diffFilename)
+			event.DifferingTransactions = result.DifferingTransactions
+			event.DifferingTransactionCount = result.DifferingTransactionCount
+			event.Providers = result.Providers
+			t.notifyMismatch(event)
+		}
+	} else {
+		t.logger.Info("Checksums are equal - skipping artifact output")
+	}
+
+	t.publishResult(result)
+
+	return &result, nil
+}
+
+// publishResult feeds a comparison result to the aggregate consumers (rate alert, digest, results store),
+// a match also lets the notifiers resolve the alerts it closes
+func (t *Tracker) publishResult(result Result) {
+	t.takeFetchDurations(&result)
+	if result.Match {
+		t.notifyMatch(result.Slot)
+	}
+
+	t.publishMu.Lock()
+	defer t.publishMu.Unlock()
+
+	t.recordMismatchRate(result.Slot, !result.Match)
+	t.recordHealth(result)
+	t.bufferResult(result)
+
+	if t.metrics != nil {
+		t.metrics.Record(result)
+	}
+	if t.emailNotifier != nil {
+		t.emailNotifier.Record(result)
+	}
+	if t.statsCheckpoint != nil {
+		t.statsCheckpoint.Record(result)
+	}
+	if t.telemetry != nil {
+		t.telemetry.Record(result)
+	}
+	if t.probes != nil {
+		t.probes.Record(result)
+	}
+	if t.grpcServer != nil {
+		t.grpcServer.Record(result)
+	}
+	t.appendResultsLog(result)
+	t.recordSlotState(result)
+}
+
+// Run compares blocks every interval until signalCtx is cancelled by a shutdown signal,
+// which aborts the in-flight comparisons
+func (t *Tracker) Run(signalCtx context.Context, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(signalCtx)
+	defer cancel()
+
+	t.logger.Info("Starting Solana Block QA Tracker", zap.Duration("effective_interval", interval))
+	t.logger.Info("Press Ctrl+C to stop the tracker")
+
+	if err := t.validateFetcherConfig(ctx); err != nil {
+		return err
+	}
+
+	if t.slotState != nil {
+		if err := t.loadSlotState(); err != nil {
+			return err
+		}
+	}
+
+	// The servers and periodic tasks stop with the tracker, whatever the reason. The periodic
+	// tasks are stopped before draining so the final digest and flush don't race with theirs.
+	stopServers, err := t.startServers()
+	if err != nil {
+		return err
+	}
+	defer stopServers()
+	stopPeriodicTasks := t.startPeriodicTasks(ctx)
+	defer stopPeriodicTasks()
+
+	// Create a ticker for periodic execution
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Comparisons run in the background so a shutdown signal is observed even while one is
+	// in flight, inFlight tracks the running comparisons. Too many consecutive errors are
+	// reported on fatalC to stop the tracker.
+	var inFlight sync.WaitGroup
+	fatalC := make(chan error, 1)
+	startComparison := func(kind string) {
+		if !t.tryAcquireComparison() {
+			t.logger.Warn("Maximum concurrent block comparisons running, skipping this one", zap.String("kind", kind))
+			return
+		}
+		if !t.hasEnoughFreeMemory() {
+			t.releaseComparison()
+			return
+		}
+
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			defer t.releaseComparison()
+
+			t.logger.Info("Running " + kind + " block comparison")
+			err := t.compareBlocksWithTimeout(ctx)
+			if err != nil && ctx.Err() != nil {
+				// Aborted by the shutdown, not a failure of the sources
+				t.logger.Info("Aborted "+kind+" block comparison on shutdown", zap.Error(err))
+				return
+			}
+			if err != nil {
+				t.logger.Error("Error in "+kind+" block comparison", zap.String("error_kind", errorKind(err)), zap.Error(err))
+				if t.statsCheckpoint != nil {
+					t.statsCheckpoint.RecordError()
+				}
+			}
+			if fatalErr := t.recordComparisonOutcome(err); fatalErr != nil {
+				select {
+				case fatalC <- fatalErr:
+				default:
+				}
+			}
+		}()
+	}
+
+	// Run the first comparison immediately
+	startComparison("initial")
+
+	// Main loop
+	for {
+		select {
+		case <-ticker.C:
+			t.checkHeadStall()
+			startComparison("periodic")
+		case err := <-fatalC:
+			t.logger.Error("Stopping tracker after too many consecutive errors", zap.Error(err))
+			stopPeriodicTasks()
+			if drainErr := t.drain(cancel, &inFlight); drainErr != nil {
+				t.logger.Error("Failed to drain in-flight work", zap.Error(drainErr))
+			}
+			return err
+		case <-signalCtx.Done():
+			t.logger.Info("Received shutdown signal, aborting in-flight comparisons",
+				zap.Duration("shutdown_timeout", t.shutdownTimeout))
+			stopPeriodicTasks()
+			return t.drain(cancel, &inFlight)
+		}
+	}
+}
+
+// startServers starts the metrics, health probes and gRPC servers that are enabled, a listen
+// error is returned once the servers already started are stopped. The returned function stops
+// them all.
+func (t *Tracker) startServers() (func(), error) {
+	var stops []func()
+	stop := func() {
+		for _, stop := range slices.Backward(stops) {
+			stop()
+		}
+	}
+
+	if t.metrics != nil {
+		if err := t.metrics.Start(t.logger); err != nil {
+			return nil, err
+		}
+		stops = append(stops, func() {
+			if err := t.metrics.Shutdown(); err != nil {
+				t.logger.Warn("Failed to shut down metrics server", zap.Error(err))
+			}
+		})
+	}
+	if t.probes != nil {
+		if err := t.probes.Start(t.logger); err != nil {
+			stop()
+			return nil, err
+		}
+		stops = append(stops, func() {
+			if err := t.probes.Shutdown(); err != nil {
+				t.logger.Warn("Failed to shut down health probes server", zap.Error(err))
+			}
+		})
+	}
+	stopGRPCServer, err := t.startGRPCServer()
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	stops = append(stops, stopGRPCServer)
+
+	return stop, nil
+}
+
+// startPeriodicTasks sends the email digest, flushes the stats checkpoint, reports telemetry and
+// logs the heartbeat in the background, each on its own ticker when enabled. The returned
+// function stops them and only returns once no task is running, it can be called several times.
+func (t *Tracker) startPeriodicTasks(ctx context.Context) func() {
+	// A nil channel blocks forever, a disabled task never fires
+	var digestC, statsC, telemetryC, heartbeatC <-chan time.Time
+	var tickers []*time.Ticker
+	newTicker := func(interval time.Duration) <-chan time.Time {
+		ticker := time.NewTicker(interval)
+		tickers = append(tickers, ticker)
+		return ticker.C
+	}
+	// The digest follows its schedule, which isn't necessarily a fixed interval
+	var digestTimer *time.Timer
+	nextDigest := func() time.Duration {
+		return time.Until(t.emailNotifier.schedule.Next(time.Now()))
+	}
+	if t.emailNotifier != nil {
+		digestTimer = time.NewTimer(nextDigest())
+		digestC = digestTimer.C
+	}
+	if t.statsCheckpoint != nil {
+		statsC = newTicker(t.statsCheckpoint.interval)
+	}
+	if t.telemetry != nil {
+		telemetryC = newTicker(t.telemetry.interval)
+	}
+	if t.heartbeatInterval > 0 {
+		heartbeatC = newTicker(t.heartbeatInterval)
+	}
+
+	stopC, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-digestC:
+				t.logger.Info("Sending email digest")
+				if err := t.emailNotifier.SendDigest(); err != nil {
+					t.logger.Error("Failed to send email digest", zap.Error(err))
+				}
+				digestTimer.Reset(nextDigest())
+			case <-statsC:
+				if err := t.statsCheckpoint.Flush(); err != nil {
+					t.logger.Error("Failed to flush stats checkpoint", zap.Error(err))
+				}
+			case <-heartbeatC:
+				t.heartbeat()
+			case <-telemetryC:
+				if err := t.telemetry.Report(ctx); err != nil {
+					t.logger.Warn("Failed to send telemetry report", zap.Error(err))
+				}
+			case <-stopC:
+				return
+			}
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		close(stopC)
+		<-done
+		for _, ticker := range tickers {
+			ticker.Stop()
+		}
+		if digestTimer != nil {
+			digestTimer.Stop()
+		}
+	})
+}
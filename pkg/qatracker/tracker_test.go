@@ -0,0 +1,49 @@
+package qatracker
+
+import (
+	"errors"
+	"testing"
+
+	pbbstream "github.com/streamingfast/bstream/pb/sf/bstream/v1"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestDecodeRPCFetcherBlock(t *testing.T) {
+	valid, err := anypb.New(&pbsol.Block{Slot: 42, Blockhash: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		block   *pbbstream.Block
+		wantErr bool
+	}{
+		{name: "nil payload", block: &pbbstream.Block{}, wantErr: true},
+		{name: "zero-length payload", block: &pbbstream.Block{Payload: &anypb.Any{TypeUrl: valid.TypeUrl}}, wantErr: true},
+		{name: "empty payload value", block: &pbbstream.Block{Payload: &anypb.Any{TypeUrl: valid.TypeUrl, Value: []byte{}}}, wantErr: true},
+		{name: "valid payload", block: &pbbstream.Block{Payload: valid}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block, err := decodeRPCFetcherBlock(test.block, 42)
+			if test.wantErr {
+				if !errors.Is(err, ErrDecode) {
+					t.Fatalf("expected a decode error, got %v", err)
+				}
+				if block != nil {
+					t.Fatalf("expected no block, got %v", block)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if block.Slot != 42 || block.Blockhash != "hash" {
+				t.Fatalf("unexpected block %v", block)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package qatracker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	pbsol "github.com/streamingfast/firehose-solana/pb/sf/solana/type/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultMaxDifferingTransactions is the number of differing transactions reported by default
+const defaultMaxDifferingTransactions = 5
+
+// TransactionDiff identifies a transaction whose sanitized checksum differs between both sources
+type TransactionDiff struct {
+	// Index is the position of the transaction in the Firehose block, or in the other block when
+	// Firehose is missing it
+	Index     int    `json:"index"`
+	Signature string `json:"signature"`
+	// FirehoseChecksum and RPCFetcherChecksum are empty on the side missing the transaction
+	FirehoseChecksum   string `json:"firehose_checksum,omitempty"`
+	RPCFetcherChecksum string `json:"rpc_fetcher_checksum,omitempty"`
+}
+
+// WithMaxDifferingTransactions sets how many differing transactions are reported with a
+// mismatch, 0 disables the per-transaction checksums
+func WithMaxDifferingTransactions(n int) Option {
+	return func(t *Tracker) {
+		t.maxDifferingTransactions = n
+	}
+}
+
+// differingTransactions computes the sanitized checksum of every transaction of two blocks,
+// pairing them by signature, and returns the first limit transactions whose checksums differ
+// (or that are present on a single side) along with the total number of differing transactions.
+// Both blocks must already be sanitized.
+func differingTransactions(a, b *pbsol.Block, limit int) ([]TransactionDiff, int, error) {
+	checksumsB := make(map[string]string, len(b.Transactions))
+	for _, trx := range b.Transactions {
+		checksum, err := transactionChecksum(trx)
+		if err != nil {
+			return nil, 0, err
+		}
+		checksumsB[string(transactionSignature(trx))] = checksum
+	}
+
+	var diffs []TransactionDiff
+	total := 0
+	record := func(diff TransactionDiff) {
+		total++
+		if len(diffs) < limit {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	paired := make(map[string]bool, len(a.Transactions))
+	for i, trx := range a.Transactions {
+		signature := transactionSignature(trx)
+		checksum, err := transactionChecksum(trx)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		other, found := checksumsB[string(signature)]
+		paired[string(signature)] = found
+		if found && other == checksum {
+			continue
+		}
+		record(TransactionDiff{Index: i, Signature: solana.Base58(signature).String(), FirehoseChecksum: checksum, RPCFetcherChecksum: other})
+	}
+	for i, trx := range b.Transactions {
+		signature := transactionSignature(trx)
+		if !paired[string(signature)] {
+			record(TransactionDiff{Index: i, Signature: solana.Base58(signature).String(), RPCFetcherChecksum: checksumsB[string(signature)]})
+		}
+	}
+
+	return diffs, total, nil
+}
+
+// transactionChecksum calculates the checksum of a single transaction
+func transactionChecksum(trx *pbsol.ConfirmedTransaction) (string, error) {
+	data, err := proto.Marshal(trx)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	return calculateChecksum(data), nil
+}
+
+// differingTransactionsLine returns the notification line listing the differing transactions,
+// or an empty string when there are none
+func differingTransactionsLine(diffs []TransactionDiff, total int) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	shown := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		shown = append(shown, fmt.Sprintf("#%d `%s`", diff.Index, diff.Signature))
+	}
+	line := fmt.Sprintf("• Differing transactions: %d (%s", total, strings.Join(shown, ", "))
+	if total > len(diffs) {
+		line += ", ..."
+	}
+	return line + ")\n"
+}
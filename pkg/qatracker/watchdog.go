@@ -0,0 +1,45 @@
+package qatracker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	pbfirehose "github.com/streamingfast/pbgo/sf/firehose/v2"
+	"go.uber.org/zap"
+)
+
+// errStreamStalled is returned when no block arrives on the Firehose stream within the receive timeout
+var errStreamStalled = errors.New("firehose stream stalled")
+
+// WithFirehoseRecvTimeout cancels and reconnects the Firehose stream when no block is received
+// within timeout, so a server stalling without erroring doesn't hang the tracker
+func WithFirehoseRecvTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.firehoseRecvTimeout = timeout
+	}
+}
+
+// recvWithWatchdog receives the next response from the stream. When the receive timeout is set
+// and elapses first, the stream is cancelled through cancel and errStreamStalled is returned.
+func (t *Tracker) recvWithWatchdog(stream pbfirehose.Stream_BlocksClient, cancel context.CancelFunc) (*pbfirehose.Response, error) {
+	if t.firehoseRecvTimeout <= 0 {
+		return stream.Recv()
+	}
+
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(t.firehoseRecvTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+
+	resp, err := stream.Recv()
+	if !watchdog.Stop() && stalled.Load() {
+		t.logger.Warn("Firehose stream stalled, no block received within timeout",
+			zap.Duration("recv_timeout", t.firehoseRecvTimeout))
+		return nil, errStreamStalled
+	}
+
+	return resp, err
+}
@@ -0,0 +1,62 @@
+package qatracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// webhookTemplateFuncs are the functions available to webhook payload templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes any value, so strings are quoted and escaped, e.g. {{ json .FirehoseFile }}
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// ParseWebhookTemplate reads the Go template rendering webhook payloads from a MismatchEvent,
+// e.g. {"text": {{ json (printf "Mismatch at slot %d" .Slot) }}}
+func ParseWebhookTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook template %s: %w", path, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// NewTemplatedWebhookNotifier creates a WebhookNotifier posting to url the JSON payload tmpl
+// renders from each mismatch event
+func NewTemplatedWebhookNotifier(url string, tmpl *template.Template) *WebhookNotifier {
+	notifier := NewWebhookNotifier(url)
+	notifier.template = tmpl
+	return notifier
+}
+
+// payload returns the body posted for event, rejecting a rendered payload that isn't valid JSON
+func (n *WebhookNotifier) payload(event MismatchEvent) ([]byte, error) {
+	if n.template == nil {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode mismatch event: %w", err)
+		}
+		return body, nil
+	}
+
+	var body bytes.Buffer
+	if err := n.template.Execute(&body, event); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("webhook template %s rendered invalid JSON for slot %d", n.template.Name(), event.Slot)
+	}
+	return body.Bytes(), nil
+}
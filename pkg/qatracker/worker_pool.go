@@ -0,0 +1,98 @@
+package qatracker
+
+import (
+	"context"
+	"sync"
+)
+
+// WithConcurrency makes range and follow modes compare up to n blocks of their Firehose stream
+// in parallel on a bounded worker pool, instead of one at a time. Every worker takes a
+// comparison slot, so parallelism is also bounded by WithMaxConcurrentComparisons.
+func WithConcurrency(n int) Option {
+	return func(t *Tracker) {
+		t.concurrency = max(n, 1)
+	}
+}
+
+// comparisonPool runs the comparisons of a stream on a bounded number of workers
+type comparisonPool struct {
+	tracker *Tracker
+	workers chan struct{}
+	running sync.WaitGroup
+}
+
+// newComparisonPool returns the worker pool of a stream, or nil when blocks are compared one
+// at a time
+func (t *Tracker) newComparisonPool() *comparisonPool {
+	if t.concurrency <= 1 {
+		return nil
+	}
+	return &comparisonPool{tracker: t, workers: make(chan struct{}, t.concurrency)}
+}
+
+// run runs compare on a worker, waiting until a worker and a comparison slot are free so the
+// stream isn't read further ahead than the pool. On a nil pool, compare runs inline.
+func (p *comparisonPool) run(ctx context.Context, compare func()) error {
+	if p == nil {
+		compare()
+		return nil
+	}
+
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := p.tracker.acquireComparison(ctx); err != nil {
+		<-p.workers
+		return err
+	}
+
+	p.running.Add(1)
+	go func() {
+		defer p.running.Done()
+		defer func() { <-p.workers }()
+		defer p.tracker.releaseComparison()
+		compare()
+	}()
+	return nil
+}
+
+// wait waits for the running comparisons to complete
+func (p *comparisonPool) wait() {
+	if p == nil {
+		return
+	}
+	p.running.Wait()
+}
+
+// orderedCursor commits the cursors of a stream whose responses are handled out of order, the
+// cursor of a response is only committed once every previous response has been handled
+type orderedCursor struct {
+	mu      sync.Mutex
+	next    uint64
+	handled map[uint64]string
+	commit  func(cursor string)
+}
+
+func newOrderedCursor(commit func(cursor string)) *orderedCursor {
+	return &orderedCursor{handled: map[uint64]string{}, commit: commit}
+}
+
+// done marks the response with sequence number seq as handled, committing the cursors of the
+// responses handled in a row since the last committed one
+func (c *orderedCursor) done(seq uint64, cursor string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handled[seq] = cursor
+	for {
+		next, found := c.handled[c.next]
+		if !found {
+			return
+		}
+		delete(c.handled, c.next)
+		c.next++
+		c.commit(next)
+	}
+}
@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+# Regenerates the Go code of the protobuf definitions into pb/, run from anywhere. Requires
+# protoc, protoc-gen-go and protoc-gen-go-grpc on the PATH:
+#
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.6
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+
+cd "$ROOT/proto"
+protoc \
+  --go_out="$ROOT/pb" --go_opt=paths=source_relative \
+  --go-grpc_out="$ROOT/pb" --go-grpc_opt=paths=source_relative \
+  sf/solana/qa/v1/qa.proto \
+  geyser/geyser.proto